@@ -0,0 +1,279 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"simple-temporal-workflow/common"
+)
+
+// Authentication methods recorded on the principal
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+)
+
+// APIKeyHeader is the header carrying static API keys
+const APIKeyHeader = "X-API-Key"
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request carries
+	// no credentials it understands, so the next authenticator can be tried
+	ErrNoCredentials = errors.New("no credentials provided")
+
+	// ErrInvalidCredentials is returned when credentials are present but rejected
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Middleware wraps an http.Handler with cross-cutting behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Authenticator resolves the principal making a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (*common.Principal, error)
+}
+
+// chainAuthenticator tries each authenticator in turn
+type chainAuthenticator []Authenticator
+
+// ChainAuthenticators returns an Authenticator that accepts the first
+// credentials recognised by any of the given authenticators
+func ChainAuthenticators(authenticators ...Authenticator) Authenticator {
+	return chainAuthenticator(authenticators)
+}
+
+func (c chainAuthenticator) Authenticate(r *http.Request) (*common.Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// APIKeyAuthenticator authenticates requests using static API keys
+type APIKeyAuthenticator struct {
+	keys map[string]common.Principal
+}
+
+// NewAPIKeyAuthenticator creates an authenticator for the given key to principal mapping
+func NewAPIKeyAuthenticator(keys map[string]common.Principal) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{keys: keys}
+}
+
+// ParseAPIKeys parses a comma separated list of "key:subject:scope1|scope2" entries
+func ParseAPIKeys(spec string) (*APIKeyAuthenticator, error) {
+	keys := make(map[string]common.Principal)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q: expected key:subject[:scopes]", entry)
+		}
+
+		principal := common.Principal{Subject: parts[1], Method: AuthMethodAPIKey}
+		if len(parts) == 3 && parts[2] != "" {
+			principal.Scopes = strings.Split(parts[2], "|")
+		}
+		keys[parts[0]] = principal
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no API keys configured")
+	}
+
+	return NewAPIKeyAuthenticator(keys), nil
+}
+
+// Authenticate looks up the API key carried in the X-API-Key header
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*common.Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	for candidate, principal := range a.keys {
+		if hmac.Equal([]byte(candidate), []byte(key)) {
+			p := principal
+			return &p, nil
+		}
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+// JWTAuthenticator verifies HMAC-SHA256 signed bearer tokens offline
+type JWTAuthenticator struct {
+	secret   []byte
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTAuthenticator creates an authenticator for HS256 tokens. Issuer and
+// audience are only checked when non-empty.
+func NewJWTAuthenticator(secret []byte, issuer, audience string) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		secret:   secret,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+}
+
+// jwtClaims represents the registered and custom claims understood by the server
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt int64           `json:"exp"`
+	NotBefore int64           `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scopes    []string        `json:"scopes"`
+}
+
+// Authenticate verifies the bearer token carried in the Authorization header
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*common.Principal, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := a.verify(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	scopes := claims.Scopes
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+
+	return &common.Principal{
+		Subject: claims.Subject,
+		Method:  AuthMethodJWT,
+		Scopes:  scopes,
+	}, nil
+}
+
+func (a *JWTAuthenticator) verify(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	// Verify header algorithm
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	if header.Algorithm != "HS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Algorithm)
+	}
+
+	// Verify signature
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	// Verify claims
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+
+	// Tokens must expire, a token without exp would be valid forever
+	now := a.now().Unix()
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("token has no expiry")
+	}
+	if now >= claims.ExpiresAt {
+		return nil, errors.New("token expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, errors.New("token not yet valid")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return nil, errors.New("unexpected issuer")
+	}
+	if a.audience != "" && !claims.hasAudience(a.audience) {
+		return nil, errors.New("unexpected audience")
+	}
+
+	return &claims, nil
+}
+
+// hasAudience handles both the string and array forms of the aud claim
+func (c *jwtClaims) hasAudience(audience string) bool {
+	var single string
+	if err := json.Unmarshal(c.Audience, &single); err == nil {
+		return single == audience
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(c.Audience, &multiple); err == nil {
+		for _, aud := range multiple {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// authorize authenticates the request and checks it was granted scope,
+// passing the principal on through the request context
+func (s *Server) authorize(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.authenticator == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := s.authenticator.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="workflows"`)
+			WriteError(w, r, http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid credentials")
+			return
+		}
+
+		if scope != "" && !principal.HasScope(scope) {
+			WriteError(w, r, http.StatusForbidden, CodeForbidden, fmt.Sprintf("Missing required scope %s", scope))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(common.WithPrincipal(r.Context(), principal)))
+	})
+}
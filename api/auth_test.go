@@ -0,0 +1,148 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signJWT(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	body := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return body + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	authenticator := NewJWTAuthenticator([]byte("secret"), "astral", "workflows")
+	exp := time.Now().Add(time.Hour).Unix()
+
+	testCases := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "valid token",
+			token: signJWT(t, "secret", map[string]any{"sub": "alice", "iss": "astral", "aud": "workflows", "exp": exp, "scope": "order:write"}),
+		},
+		{
+			name:  "audience list",
+			token: signJWT(t, "secret", map[string]any{"sub": "alice", "iss": "astral", "aud": []string{"other", "workflows"}, "exp": exp}),
+		},
+		{
+			name:    "wrong secret",
+			token:   signJWT(t, "other", map[string]any{"sub": "alice", "iss": "astral", "aud": "workflows", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   signJWT(t, "secret", map[string]any{"sub": "alice", "iss": "astral", "aud": "workflows", "exp": time.Now().Add(-time.Minute).Unix()}),
+			wantErr: true,
+		},
+		{
+			name:    "without expiry",
+			token:   signJWT(t, "secret", map[string]any{"sub": "alice", "iss": "astral", "aud": "workflows"}),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signJWT(t, "secret", map[string]any{"sub": "alice", "iss": "someone", "aud": "workflows", "exp": exp}),
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   "not-a-token",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+			principal, err := authenticator.Authenticate(r)
+
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "alice", principal.Subject)
+				assert.Equal(t, AuthMethodJWT, principal.Method)
+			}
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	authenticator, err := ParseAPIKeys("key-1:alice:order:write|payment:refund, key-2:bob")
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set(APIKeyHeader, "key-1")
+	principal, err := authenticator.Authenticate(r)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+	assert.Equal(t, []string{"order:write", "payment:refund"}, principal.Scopes)
+
+	r.Header.Set(APIKeyHeader, "key-3")
+	_, err = authenticator.Authenticate(r)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = ParseAPIKeys("missing-subject")
+	assert.Error(t, err)
+}
+
+func TestServer_Authorization(t *testing.T) {
+	apiKeys, err := ParseAPIKeys("writer:alice:order:write,reader:bob:order:read")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		apiKey     string
+		wantStatus int
+	}{
+		{"no credentials", "", http.StatusUnauthorized},
+		{"unknown key", "nope", http.StatusUnauthorized},
+		{"missing scope", "reader", http.StatusForbidden},
+		{"authorized", "writer", http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orderDomain := &stubDomain{}
+			server := NewServer(orderDomain)
+			server.SetAuthenticator(ChainAuthenticators(apiKeys, NewJWTAuthenticator([]byte("secret"), "", "")))
+			mux := http.NewServeMux()
+			server.RegisterRoutes(mux)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"orderId":"order-123"}`))
+			if tc.apiKey != "" {
+				r.Header.Set(APIKeyHeader, tc.apiKey)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus == http.StatusCreated {
+				require.NotNil(t, orderDomain.principal)
+				assert.Equal(t, "alice", orderDomain.principal.Subject)
+			} else {
+				assert.Nil(t, orderDomain.principal)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.temporal.io/api/serviceerror"
+	"simple-temporal-workflow/common"
+)
+
+// Error codes returned in the error envelope
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeAlreadyStarted   = "workflow_already_started"
+	CodeNotFound         = "workflow_not_found"
+	CodeUnavailable      = "service_unavailable"
+	CodeTimeout          = "timeout"
+	CodeInternal         = "internal_error"
+)
+
+// RequestIDHeader carries the request ID used to correlate logs and responses
+const RequestIDHeader = "X-Request-ID"
+
+// ErrorResponse is the JSON envelope returned for every failed request
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a failed request
+type ErrorBody struct {
+	Code       string             `json:"code"`
+	Message    string             `json:"message"`
+	RequestID  string             `json:"requestId,omitempty"`
+	Violations []common.Violation `json:"violations,omitempty"`
+}
+
+type requestIDKey struct{}
+
+// withRequestID assigns every request an ID, reusing the caller's X-Request-ID
+// when present, and echoes it back in the response headers
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, requestID)))
+	})
+}
+
+// RequestID returns the ID assigned to the request carried by ctx
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WriteError responds with the JSON error envelope
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeErrorBody(w, status, ErrorBody{
+		Code:      code,
+		Message:   message,
+		RequestID: RequestID(r.Context()),
+	})
+}
+
+// writeValidationError responds with 400 and the list of field violations
+func writeValidationError(w http.ResponseWriter, r *http.Request, err *common.ValidationError) {
+	writeErrorBody(w, http.StatusBadRequest, ErrorBody{
+		Code:       CodeValidationFailed,
+		Message:    "request validation failed",
+		RequestID:  RequestID(r.Context()),
+		Violations: err.Violations,
+	})
+}
+
+// writeWorkflowError maps a failed workflow operation, such as "start
+// ProcessOrder workflow", onto an HTTP status and error code
+func writeWorkflowError(w http.ResponseWriter, r *http.Request, operation string, err error) {
+	var validationErr *common.ValidationError
+	if errors.As(err, &validationErr) {
+		writeValidationError(w, r, validationErr)
+		return
+	}
+
+	status, code, message := classifyError(err)
+	slog.ErrorContext(r.Context(), "Workflow operation failed", "RequestID", RequestID(r.Context()), "Operation", operation, "Error", err)
+	WriteError(w, r, status, code, message)
+}
+
+// classifyError maps Temporal service errors onto HTTP semantics
+func classifyError(err error) (status int, code, message string) {
+	var (
+		alreadyStarted    *serviceerror.WorkflowExecutionAlreadyStarted
+		namespaceNotFound *serviceerror.NamespaceNotFound
+		invalidArgument   *serviceerror.InvalidArgument
+		deadlineExceeded  *serviceerror.DeadlineExceeded
+		unavailable       *serviceerror.Unavailable
+		notFound          *serviceerror.NotFound
+	)
+
+	switch {
+	case errors.As(err, &alreadyStarted):
+		return http.StatusConflict, CodeAlreadyStarted, "Workflow is already running"
+	case errors.As(err, &notFound):
+		return http.StatusNotFound, CodeNotFound, "Workflow not found"
+	case errors.As(err, &namespaceNotFound), errors.As(err, &unavailable):
+		return http.StatusServiceUnavailable, CodeUnavailable, "Workflow service unavailable"
+	case errors.As(err, &invalidArgument):
+		return http.StatusBadRequest, CodeInvalidRequest, invalidArgument.Message
+	case errors.As(err, &deadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, CodeTimeout, "Timed out waiting for the workflow service"
+	default:
+		return http.StatusInternalServerError, CodeInternal, "Workflow request failed"
+	}
+}
+
+func writeErrorBody(w http.ResponseWriter, status int, body ErrorBody) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: body})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"simple-temporal-workflow/common"
+)
+
+// IdempotencyKeyHeader lets clients safely retry workflow-trigger requests
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// withIdempotencyKey passes the Idempotency-Key header on to the workflow
+// clients, which map it to a deterministic workflow ID
+func withIdempotencyKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > common.MaxIdempotencyKeyLength {
+			WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest,
+				fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, common.MaxIdempotencyKeyLength))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(common.WithIdempotencyKey(r.Context(), key)))
+	})
+}
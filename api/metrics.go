@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"simple-temporal-workflow/metrics"
+)
+
+// RequestDuration is the latency histogram of the API routes, labelled with
+// the route pattern, method and response status
+const RequestDuration = "astral_api_request_duration_seconds"
+
+// methodOther labels requests whose method is not one of the standard ones,
+// so clients cannot create label values at will
+const methodOther = "other"
+
+// SetMetrics records the latency and status of every request to the
+// server's routes in registry. Without a registry requests are not recorded.
+func (s *Server) SetMetrics(registry *metrics.Registry) {
+	s.metrics = metrics.Register(registry, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    RequestDuration,
+		Help:    "Latency of API requests by route, method and status.",
+		Buckets: metrics.DefaultBuckets,
+	}, []string{"route", "method", "status"}))
+}
+
+// withMetrics records the latency and status of requests to pattern
+func withMetrics(requestDuration *prometheus.HistogramVec, pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		requestDuration.WithLabelValues(pattern, methodLabel(r.Method), strconv.Itoa(recorder.status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// methodLabel maps a request method onto the fixed set of method labels
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodHead, http.MethodOptions:
+		return method
+	default:
+		return methodOther
+	}
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
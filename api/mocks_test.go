@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"simple-temporal-workflow/common"
+)
+
+// stubRequest mirrors a domain workflow-trigger request
+type stubRequest struct {
+	OrderID string `json:"orderId" validate:"required,max=128"`
+	UserID  string `json:"userId,omitempty" validate:"max=128"`
+}
+
+// stubDomain records the context each workflow was started with
+type stubDomain struct {
+	principal      *common.Principal
+	idempotencyKey string
+	replayed       bool
+	err            error
+	span           trace.SpanContext
+}
+
+func (d *stubDomain) ProcessOrder(ctx context.Context, req stubRequest) (*common.WorkflowResult, error) {
+	d.principal, _ = common.PrincipalFromContext(ctx)
+	d.idempotencyKey, _ = common.IdempotencyKeyFromContext(ctx)
+	d.span = trace.SpanContextFromContext(ctx)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &common.WorkflowResult{WorkflowID: "process-order-1", RunID: "run-1", Replayed: d.replayed}, nil
+}
+
+func (d *stubDomain) SignalOrder(ctx context.Context, req stubRequest) (*common.WorkflowResult, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &common.WorkflowResult{WorkflowID: "process-order-" + req.OrderID, Message: "signalled"}, nil
+}
+
+func (d *stubDomain) Routes(router *Router) {
+	router.Handle("/api/workflows/order/process", "order:write", WorkflowHandler("ProcessOrder", d.ProcessOrder))
+	router.Handle("/api/workflows/order/signal", "order:write", SignalHandler("update", d.SignalOrder))
+}
+
+// malformedRequest carries a validation tag that can never be checked
+type malformedRequest struct {
+	OrderID string `json:"orderId" validate:"required,max=many"`
+}
+
+// malformedDomain routes a handler whose request has malformed validation rules
+type malformedDomain struct{}
+
+func (d malformedDomain) Routes(router *Router) {
+	router.Handle("/api/workflows/order/malformed", "order:write", WorkflowHandler("ProcessOrder", func(ctx context.Context, req malformedRequest) (*common.WorkflowResult, error) {
+		return &common.WorkflowResult{WorkflowID: "process-order-" + req.OrderID}, nil
+	}))
+}
@@ -1,56 +1,184 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 
-	"simple-temporal-workflow/order"
+	"github.com/prometheus/client_golang/prometheus"
+	"simple-temporal-workflow/common"
 )
 
+// RouteProvider is implemented by domains exposing workflow-trigger endpoints
+type RouteProvider interface {
+	Routes(router *Router)
+}
+
 // Server handles HTTP requests for triggering workflows
 type Server struct {
-	orderClient order.Client
+	providers     []RouteProvider
+	authenticator Authenticator
+	middleware    []Middleware
+	metrics       *prometheus.HistogramVec
 }
 
-// NewServer creates a new HTTP server for workflow triggers
-func NewServer(orderClient order.Client) *Server {
+// NewServer creates a new HTTP server for workflow triggers exposing the
+// routes of every provider
+func NewServer(providers ...RouteProvider) *Server {
 	return &Server{
-		orderClient: orderClient,
+		providers: providers,
 	}
 }
 
-// RegisterRoutes sets up HTTP routes for workflow triggers
-func (s *Server) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/api/workflows/order/process", s.handleProcessOrder)
+// SetAuthenticator enables authentication on every route. Without an
+// authenticator the routes are open.
+func (s *Server) SetAuthenticator(authenticator Authenticator) {
+	s.authenticator = authenticator
 }
 
+// Use appends middleware to the chain applied to every route
+func (s *Server) Use(middleware ...Middleware) {
+	s.middleware = append(s.middleware, middleware...)
+}
 
-func (s *Server) handleProcessOrder(w http.ResponseWriter, r *http.Request) {
+// RegisterRoutes sets up HTTP routes for workflow triggers. It fails when
+// the validation rules of a route's request type are malformed, leaving
+// that route unregistered, or when a provider reports a failure with
+// Router.Fail.
+func (s *Server) RegisterRoutes(mux *http.ServeMux) error {
+	router := &Router{server: s, mux: mux}
+	for _, provider := range s.providers {
+		provider.Routes(router)
+	}
+	return errors.Join(router.errs...)
+}
+
+// Router registers domain routes on the server's mux
+type Router struct {
+	server *Server
+	mux    *http.ServeMux
+	errs   []error
+}
+
+// Fail records an error returned by RegisterRoutes, for providers that
+// cannot register their routes
+func (r *Router) Fail(err error) {
+	r.errs = append(r.errs, err)
+}
+
+// requestChecker is implemented by handlers decoding a request body, see
+// WorkflowHandler
+type requestChecker interface {
+	checkRequest() error
+}
+
+// Handle registers a route guarded by scope and wrapped in the middleware
+// chain. The validation rules of the request type of handlers created by
+// WorkflowHandler or SignalHandler are compiled here, so a malformed rule
+// fails RegisterRoutes instead of a request.
+func (r *Router) Handle(pattern, scope string, handler http.Handler) {
+	if checker, ok := handler.(requestChecker); ok {
+		if err := checker.checkRequest(); err != nil {
+			r.errs = append(r.errs, fmt.Errorf("route %s: %w", pattern, err))
+			return
+		}
+	}
+
+	var h http.Handler = r.server.authorize(scope, handler)
+	for i := len(r.server.middleware) - 1; i >= 0; i-- {
+		h = r.server.middleware[i](h)
+	}
+	h = withRequestID(withIdempotencyKey(h))
+	if r.server.metrics != nil {
+		h = withMetrics(r.server.metrics, pattern, h)
+	}
+	r.mux.Handle(pattern, withTracing(pattern, h))
+}
+
+// requestHandler is a handler decoding request bodies of type T
+type requestHandler[T any] http.HandlerFunc
+
+func (h requestHandler[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h(w, r)
+}
+
+func (h requestHandler[T]) checkRequest() error {
+	return common.CompileValidation(new(T))
+}
+
+// WorkflowHandler adapts a domain client method starting a workflow into a
+// POST handler that decodes and validates the request body
+func WorkflowHandler[T any](workflowType string, start func(context.Context, T) (*common.WorkflowResult, error)) http.Handler {
+	return requestHandler[T](func(w http.ResponseWriter, r *http.Request) {
+		var req T
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+
+		// Execute workflow through client
+		result, err := start(r.Context(), req)
+		if err != nil {
+			writeWorkflowError(w, r, "start "+workflowType+" workflow", err)
+			return
+		}
+
+		writeWorkflowResult(w, result)
+	})
+}
+
+// SignalHandler adapts a domain client method signalling a running workflow
+// into a POST handler that responds with 202 once the signal is delivered
+func SignalHandler[T any](signalName string, signal func(context.Context, T) (*common.WorkflowResult, error)) http.Handler {
+	return requestHandler[T](func(w http.ResponseWriter, r *http.Request) {
+		var req T
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+
+		result, err := signal(r.Context(), req)
+		if err != nil {
+			writeWorkflowError(w, r, "send "+signalName+" signal", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// decodeRequest reads and validates the JSON body of a workflow-trigger
+// request, reporting whether the handler should continue
+func decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+		WriteError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed")
+		return false
 	}
 
-	var req order.ProcessOrderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		WriteError(w, r, http.StatusBadRequest, CodeInvalidRequest, "Invalid JSON")
+		return false
 	}
 
-	if req.OrderID == "" {
-		http.Error(w, "orderId is required", http.StatusBadRequest)
-		return
+	if err := common.Validate(req); err != nil {
+		writeWorkflowError(w, r, "validate request", err)
+		return false
 	}
 
-	// Execute workflow through client
-	result, err := s.orderClient.ProcessOrder(r.Context(), req)
-	if err != nil {
-		log.Printf("Failed to start ProcessOrder workflow: %v", err)
-		http.Error(w, "Failed to start workflow", http.StatusInternalServerError)
-		return
+	return true
+}
+
+// writeWorkflowResult responds with 201 for newly started workflows and 200
+// when an idempotent request replayed an existing one
+func writeWorkflowResult(w http.ResponseWriter, result *common.WorkflowResult) {
+	status := http.StatusCreated
+	if result.Replayed {
+		status = http.StatusOK
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(result)
 }
\ No newline at end of file
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.temporal.io/api/serviceerror"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/metrics"
+)
+
+func TestServer_ProcessOrderValidation(t *testing.T) {
+	server := NewServer(&stubDomain{})
+	mux := http.NewServeMux()
+	require.NoError(t, server.RegisterRoutes(mux))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"userId":"user-alice"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, CodeValidationFailed, body.Error.Code)
+	assert.NotEmpty(t, body.Error.RequestID)
+	assert.Equal(t, w.Header().Get(RequestIDHeader), body.Error.RequestID)
+	assert.Equal(t, []common.Violation{{Field: "orderId", Rule: "required", Message: "orderId is required"}}, body.Error.Violations)
+}
+
+func TestServer_MalformedValidationRules(t *testing.T) {
+	server := NewServer(&stubDomain{}, malformedDomain{})
+	mux := http.NewServeMux()
+
+	err := server.RegisterRoutes(mux)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "route /api/workflows/order/malformed")
+	assert.Contains(t, err.Error(), `invalid max rule "many"`)
+
+	// The malformed route is skipped; the others are still served
+	r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/malformed", strings.NewReader(`{"orderId":"order-1"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"orderId":"order-1"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestServer_ErrorMapping(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"already started", serviceerror.NewWorkflowExecutionAlreadyStarted("started", "", "run-1"), http.StatusConflict, CodeAlreadyStarted},
+		{"namespace not found", serviceerror.NewNamespaceNotFound("claude"), http.StatusServiceUnavailable, CodeUnavailable},
+		{"workflow not found", serviceerror.NewNotFound("workflow not found"), http.StatusNotFound, CodeNotFound},
+		{"invalid argument", serviceerror.NewInvalidArgument("bad search attribute"), http.StatusBadRequest, CodeInvalidRequest},
+		{"service deadline", serviceerror.NewDeadlineExceeded("deadline"), http.StatusGatewayTimeout, CodeTimeout},
+		{"context deadline", fmt.Errorf("failed to start: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, CodeTimeout},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(&stubDomain{err: fmt.Errorf("failed to start ProcessOrder.v1 workflow: %w", tc.err)})
+			mux := http.NewServeMux()
+			server.RegisterRoutes(mux)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"orderId":"order-123"}`))
+			r.Header.Set(RequestIDHeader, "req-123")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			var body ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.Equal(t, tc.wantCode, body.Error.Code)
+			assert.Equal(t, "req-123", body.Error.RequestID)
+		})
+	}
+}
+
+func TestServer_IdempotencyKey(t *testing.T) {
+	testCases := []struct {
+		name       string
+		replayed   bool
+		wantStatus int
+	}{
+		{"new workflow", false, http.StatusCreated},
+		{"replayed workflow", true, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orderDomain := &stubDomain{replayed: tc.replayed}
+			server := NewServer(orderDomain)
+			mux := http.NewServeMux()
+			server.RegisterRoutes(mux)
+
+			r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"orderId":"order-123"}`))
+			r.Header.Set(IdempotencyKeyHeader, "retry-key")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			assert.Equal(t, "retry-key", orderDomain.idempotencyKey)
+
+			var result common.WorkflowResult
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+			assert.Equal(t, "process-order-1", result.WorkflowID)
+			assert.Equal(t, "run-1", result.RunID)
+		})
+	}
+
+	t.Run("key too long", func(t *testing.T) {
+		server := NewServer(&stubDomain{})
+		mux := http.NewServeMux()
+		server.RegisterRoutes(mux)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"orderId":"order-123"}`))
+		r.Header.Set(IdempotencyKeyHeader, strings.Repeat("k", common.MaxIdempotencyKeyLength+1))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+
+func TestServer_SignalHandler(t *testing.T) {
+	t.Run("signal delivered", func(t *testing.T) {
+		server := NewServer(&stubDomain{})
+		mux := http.NewServeMux()
+		server.RegisterRoutes(mux)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/signal", strings.NewReader(`{"orderId":"order-123"}`))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		var result common.WorkflowResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, "process-order-order-123", result.WorkflowID)
+	})
+
+	t.Run("workflow not running", func(t *testing.T) {
+		server := NewServer(&stubDomain{err: serviceerror.NewNotFound("workflow execution already completed")})
+		mux := http.NewServeMux()
+		server.RegisterRoutes(mux)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/signal", strings.NewReader(`{"orderId":"order-123"}`))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServer_Metrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	server := NewServer(&stubDomain{})
+	server.SetMetrics(registry)
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	for _, body := range []string{`{"orderId":"order-123"}`, `{}`} {
+		r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(body))
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	// Arbitrary methods share a single label value
+	for _, method := range []string{"FOO", "BAR"} {
+		r := httptest.NewRequest(method, "/api/workflows/order/process", nil)
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	exposition := w.Body.String()
+	assert.Contains(t, exposition, `astral_api_request_duration_seconds_count{method="POST",route="/api/workflows/order/process",status="201"} 1`)
+	assert.Contains(t, exposition, `astral_api_request_duration_seconds_count{method="POST",route="/api/workflows/order/process",status="400"} 1`)
+	assert.Contains(t, exposition, `astral_api_request_duration_seconds_count{method="other",route="/api/workflows/order/process",status="405"} 2`)
+	assert.NotContains(t, exposition, `method="FOO"`)
+}
+
+func TestServer_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	orderDomain := &stubDomain{}
+	server := NewServer(orderDomain)
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	r := httptest.NewRequest(http.MethodPost, "/api/workflows/order/process", strings.NewReader(`{"orderId":"order-123"}`))
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// The request span continues the caller's trace and is handed to the client
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "POST /api/workflows/order/process", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.status_code", http.StatusCreated))
+	assert.Equal(t, spans[0].SpanContext().SpanID(), orderDomain.span.SpanID())
+}
@@ -0,0 +1,107 @@
+package checkout
+
+import (
+	"context"
+	"fmt"
+
+	"simple-temporal-workflow/checkout/workflows"
+	"simple-temporal-workflow/common"
+	orderworkflows "simple-temporal-workflow/order/workflows"
+	paymentworkflows "simple-temporal-workflow/payment/workflows"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// CheckoutRequest represents a request to charge a payment and fulfill the order it pays for
+type CheckoutRequest struct {
+	OrderID   string       `json:"orderId" validate:"required,max=128"`
+	PaymentID string       `json:"paymentId" validate:"required,max=128"`
+	Amount    common.Money `json:"amount" validate:"required,positive"`
+	UserID    string       `json:"userId,omitempty" validate:"max=128"` // Optional for search attributes
+}
+
+// CheckoutStatus combines the progress of a Checkout workflow with the
+// progress of its payment and order child workflows
+type CheckoutStatus struct {
+	workflows.CheckoutProgress
+	PaymentProgress *paymentworkflows.ProcessPaymentProgress `json:"paymentProgress,omitempty"`
+	OrderProgress   *orderworkflows.ProcessOrderProgress     `json:"orderProgress,omitempty"`
+}
+
+// Client provides methods to execute checkout workflows
+type Client interface {
+	Checkout(ctx context.Context, req CheckoutRequest) (*common.WorkflowResult, error)
+	GetCheckoutProgress(ctx context.Context, workflowID, runID string) (*CheckoutStatus, error)
+	GetCheckoutState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error)
+}
+
+// checkoutClient implements the Client interface
+type checkoutClient struct {
+	commonClient *common.Client
+}
+
+// NewClient creates a new checkout workflow client
+func NewClient(temporalClient temporalclient.Client, taskQueue string) Client {
+	return &checkoutClient{
+		commonClient: common.NewClient(temporalClient, taskQueue),
+	}
+}
+
+// Checkout starts a Checkout workflow
+func (c *checkoutClient) Checkout(ctx context.Context, req CheckoutRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workflowInput := workflows.CheckoutRequest{
+		OrderID:   req.OrderID,
+		PaymentID: req.PaymentID,
+		Amount:    req.Amount,
+	}
+
+	// Build search attributes
+	searchAttributes := make(map[string]any)
+	if req.UserID != "" {
+		searchAttributes["userId"] = req.UserID
+	}
+
+	return c.commonClient.ExecuteWorkflow(ctx, common.WorkflowExecutionParams{
+		WorkflowType:     CheckoutWorkflow,
+		WorkflowIDPrefix: "checkout",
+		WorkflowInput:    workflowInput,
+		SearchAttributes: searchAttributes,
+		SuccessMessage:   fmt.Sprintf("Checkout workflow started for order %s", req.OrderID),
+	})
+}
+
+// GetCheckoutProgress queries the progress of a Checkout workflow together
+// with the progress of the payment and order child workflows that have started
+func (c *checkoutClient) GetCheckoutProgress(ctx context.Context, workflowID, runID string) (*CheckoutStatus, error) {
+	var status CheckoutStatus
+	if err := c.commonClient.QueryWorkflow(ctx, workflowID, runID, common.QueryProgress, &status.CheckoutProgress); err != nil {
+		return nil, err
+	}
+
+	if child := status.Payment; child != nil && child.RunID != "" {
+		status.PaymentProgress = &paymentworkflows.ProcessPaymentProgress{}
+		if err := c.commonClient.QueryWorkflow(ctx, child.WorkflowID, child.RunID, common.QueryProgress, status.PaymentProgress); err != nil {
+			return nil, err
+		}
+	}
+	if child := status.Order; child != nil && child.RunID != "" {
+		status.OrderProgress = &orderworkflows.ProcessOrderProgress{}
+		if err := c.commonClient.QueryWorkflow(ctx, child.WorkflowID, child.RunID, common.QueryProgress, status.OrderProgress); err != nil {
+			return nil, err
+		}
+	}
+
+	return &status, nil
+}
+
+// GetCheckoutState queries the compact state of a running or completed Checkout workflow
+func (c *checkoutClient) GetCheckoutState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error) {
+	var state common.WorkflowState
+	if err := c.commonClient.QueryWorkflow(ctx, workflowID, runID, common.QueryState, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
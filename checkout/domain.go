@@ -0,0 +1,76 @@
+package checkout
+
+import (
+	"errors"
+
+	"simple-temporal-workflow/api"
+	"simple-temporal-workflow/checkout/workflows"
+	"simple-temporal-workflow/domain"
+	orderchild "simple-temporal-workflow/order/child"
+	paymentchild "simple-temporal-workflow/payment/child"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Scopes required by the checkout endpoints
+const (
+	ScopeWrite = "checkout:write"
+)
+
+func init() {
+	domain.Register(NewDomain())
+}
+
+// Domain wires the checkout workflow and client into the service
+type Domain struct {
+	orchestrator *Orchestrator
+	client       Client
+}
+
+// NewDomain creates the checkout domain, starting children on the
+// checkout's task queue until SetTaskQueues is called
+func NewDomain() *Domain {
+	return &Domain{
+		orchestrator: NewOrchestrator(NewWorkflows(workflows.TaskQueues{})),
+	}
+}
+
+// Name returns the domain name
+func (d *Domain) Name() string {
+	return "checkout"
+}
+
+// Register registers the checkout workflow with the worker
+func (d *Domain) Register(w worker.Worker) {
+	d.orchestrator.RegisterWithWorker(w)
+}
+
+// SetTaskQueues starts the payment and order children of checkouts on the
+// task queues of their domains
+func (d *Domain) SetTaskQueues(taskQueueOf func(domain string) string) {
+	d.orchestrator = NewOrchestrator(NewWorkflows(workflows.TaskQueues{
+		Payment: taskQueueOf(paymentchild.Domain),
+		Order:   taskQueueOf(orderchild.Domain),
+	}))
+}
+
+// NewClient creates the checkout workflow client
+func (d *Domain) NewClient(temporalClient client.Client, taskQueue string) {
+	d.client = NewClient(temporalClient, taskQueue)
+}
+
+// Client returns the checkout workflow client created by NewClient
+func (d *Domain) Client() Client {
+	return d.client
+}
+
+// Routes registers the checkout workflow-trigger endpoints, failing route
+// registration when NewClient was not called as the handlers are bound to
+// the client
+func (d *Domain) Routes(router *api.Router) {
+	if d.client == nil {
+		router.Fail(errors.New("checkout domain routes registered before NewClient"))
+		return
+	}
+	router.Handle("/api/workflows/checkout", ScopeWrite, api.WorkflowHandler("Checkout", d.client.Checkout))
+}
@@ -0,0 +1,11 @@
+package checkout
+
+import (
+	"simple-temporal-workflow/checkout/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Workflows defines the checkout workflow interface
+type Workflows interface {
+	Checkout(ctx workflow.Context, req workflows.CheckoutRequest) (string, error)
+}
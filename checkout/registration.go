@@ -0,0 +1,29 @@
+package checkout
+
+import (
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Workflow types registered by the checkout domain
+const (
+	CheckoutWorkflow = "Checkout.v1"
+)
+
+type Orchestrator struct {
+	workflows Workflows
+}
+
+func NewOrchestrator(workflows Workflows) *Orchestrator {
+	return &Orchestrator{
+		workflows: workflows,
+	}
+}
+
+func (o *Orchestrator) RegisterWithWorker(w worker.Worker) {
+	// Register workflows with versioning. The payment and order child
+	// workflows are registered by their own domains.
+	w.RegisterWorkflowWithOptions(o.workflows.Checkout, workflow.RegisterOptions{
+		Name: CheckoutWorkflow,
+	})
+}
@@ -0,0 +1,13 @@
+package checkout
+
+import (
+	"testing"
+
+	"simple-temporal-workflow/replay"
+)
+
+// TestReplay replays the recorded histories in testdata against the
+// workflows the checkout domain registers
+func TestReplay(t *testing.T) {
+	replay.New(NewDomain().Register).Run(t, "testdata")
+}
@@ -0,0 +1,23 @@
+package checkout
+
+import (
+	"simple-temporal-workflow/checkout/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// checkoutWorkflowAdapter adapts the checkout workflows to the domain interface
+type checkoutWorkflowAdapter struct {
+	workflows *workflows.Workflows
+}
+
+func (a *checkoutWorkflowAdapter) Checkout(ctx workflow.Context, req workflows.CheckoutRequest) (string, error) {
+	return a.workflows.Checkout(ctx, req)
+}
+
+// NewWorkflows creates a new checkout workflows service starting children
+// on taskQueues
+func NewWorkflows(taskQueues workflows.TaskQueues) Workflows {
+	return &checkoutWorkflowAdapter{
+		workflows: workflows.NewWorkflows(taskQueues),
+	}
+}
@@ -0,0 +1,165 @@
+package workflows
+
+import (
+	"errors"
+	"fmt"
+
+	"simple-temporal-workflow/common"
+	orderchild "simple-temporal-workflow/order/child"
+	orderworkflows "simple-temporal-workflow/order/workflows"
+	paymentactivities "simple-temporal-workflow/payment/activities"
+	paymentchild "simple-temporal-workflow/payment/child"
+	paymentworkflows "simple-temporal-workflow/payment/workflows"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Checkout steps reported through the progress query
+const (
+	StepProcessPayment = "process_payment"
+	StepProcessOrder   = "process_order"
+	StepRefundPayment  = "refund_payment"
+)
+
+// CheckoutRequest represents a checkout workflow input
+type CheckoutRequest struct {
+	OrderID   string       `json:"orderId"`
+	PaymentID string       `json:"paymentId"`
+	Amount    common.Money `json:"amount"`
+}
+
+// ChildWorkflow identifies a child workflow started by Checkout and its result
+type ChildWorkflow struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId,omitempty"`
+	Result     string `json:"result,omitempty"`
+}
+
+// CheckoutProgress represents the progress of a Checkout workflow. The child
+// workflows can be queried by ID for their own progress.
+type CheckoutProgress struct {
+	common.Progress
+	Payment *ChildWorkflow `json:"payment,omitempty"`
+	Order   *ChildWorkflow `json:"order,omitempty"`
+	Refund  *ChildWorkflow `json:"refund,omitempty"`
+}
+
+// Checkout charges the payment and then fulfills the order, each in a child
+// workflow. Cancelling the checkout cancels the running child. When the
+// order cannot be fulfilled, or the checkout is cancelled once the payment
+// child has started, the payment is refunded.
+func (w *Workflows) Checkout(ctx workflow.Context, req CheckoutRequest) (string, error) {
+	// Expose intermediate state through query handlers
+	var progress CheckoutProgress
+	err := common.SetProgressQueryHandlers(ctx,
+		func() CheckoutProgress { return progress },
+		progress.State,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to register query handlers: %w", err)
+	}
+
+	// Step 1: Charge the payment
+	progress.StartStep(StepProcessPayment)
+	paymentRun := paymentchild.ProcessPayment(ctx, paymentworkflows.PaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount},
+		common.WithChildTaskQueue(w.taskQueues.Payment),
+	)
+	progress.Payment = &ChildWorkflow{WorkflowID: paymentRun.WorkflowID()}
+	progress.Payment.Result, err = awaitChild(ctx, paymentRun, progress.Payment)
+	if err != nil {
+		err = fmt.Errorf("failed to process payment: %w", err)
+		progress.Fail(err)
+		// A payment cancelled along with the checkout may have charged
+		// before it stopped
+		if temporal.IsCanceledError(ctx.Err()) && progress.Payment.RunID != "" {
+			return "", w.refund(ctx, req, &progress, err)
+		}
+		return "", err
+	}
+	progress.CompleteStep()
+
+	// Step 2: Fulfill the order
+	progress.StartStep(StepProcessOrder)
+	orderRun := orderchild.ProcessOrderV2(ctx, orderworkflows.OrderRequest{OrderID: req.OrderID},
+		common.WithChildTaskQueue(w.taskQueues.Order),
+	)
+	progress.Order = &ChildWorkflow{WorkflowID: orderRun.WorkflowID()}
+	progress.Order.Result, err = awaitChild(ctx, orderRun, progress.Order)
+	if err != nil {
+		err = fmt.Errorf("failed to process order: %w", err)
+		progress.Fail(err)
+		return "", w.refund(ctx, req, &progress, err)
+	}
+	progress.CompleteStep()
+
+	return fmt.Sprintf("Checkout completed for order %s and payment %s", req.OrderID, req.PaymentID), nil
+}
+
+// refund returns the charged amount through a RefundPayment child and
+// returns cause, or the refund failure as well when the refund did not go
+// through. A payment that was never charged needs no refund. It runs on a
+// disconnected context so it still completes after the checkout is
+// cancelled.
+func (w *Workflows) refund(ctx workflow.Context, req CheckoutRequest, progress *CheckoutProgress, cause error) error {
+	if temporal.IsCanceledError(ctx.Err()) {
+		ctx, _ = workflow.NewDisconnectedContext(ctx)
+	}
+
+	logger(ctx).Warn("Refunding checkout payment", "PaymentID", req.PaymentID, "Error", cause)
+	progress.StartStep(StepRefundPayment)
+	checkoutID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	refundRun := paymentchild.RefundPayment(ctx, paymentworkflows.RefundRequest{
+		PaymentID: req.PaymentID,
+		RefundID:  checkoutID + "-refund",
+		Amount:    req.Amount,
+		Reason:    "checkout failed",
+	},
+		// The refund keeps the derived child ID rather than the one shared by
+		// refunds started through the API, so one in flight cannot keep the
+		// compensation from starting. Let it finish if the checkout closes
+		// first.
+		common.WithParentClosePolicy(enums.PARENT_CLOSE_POLICY_ABANDON),
+		common.WithChildTaskQueue(w.taskQueues.Payment),
+	)
+
+	progress.Refund = &ChildWorkflow{WorkflowID: refundRun.WorkflowID()}
+	_, err := awaitChild(ctx, refundRun, progress.Refund)
+	if isNotCharged(err) {
+		logger(ctx).Info("Checkout payment was not charged, nothing to refund", "PaymentID", req.PaymentID)
+		progress.CompleteStep()
+		return cause
+	}
+	if err != nil {
+		logger(ctx).Error("Failed to refund checkout payment", "PaymentID", req.PaymentID, "Error", err)
+		err = errors.Join(cause, fmt.Errorf("failed to refund payment %s: %w", req.PaymentID, err))
+		progress.Fail(err)
+		return err
+	}
+	progress.CompleteStep()
+	return cause
+}
+
+// isNotCharged reports whether err is the failure of a refund of a payment
+// that was never charged
+func isNotCharged(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if applicationErr, ok := err.(*temporal.ApplicationError); ok && applicationErr.Type() == paymentactivities.ErrTypePaymentNotCharged {
+			return true
+		}
+	}
+	return false
+}
+
+// awaitChild waits for a child workflow, recording its run ID in child as
+// soon as it starts so the child can be queried while it runs
+func awaitChild[T any](ctx workflow.Context, run *common.ChildRun[T], child *ChildWorkflow) (T, error) {
+	execution, err := run.Execution(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	child.RunID = execution.RunID
+
+	return run.Get(ctx)
+}
@@ -0,0 +1,266 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"simple-temporal-workflow/common"
+	orderchild "simple-temporal-workflow/order/child"
+	orderworkflows "simple-temporal-workflow/order/workflows"
+	paymentactivities "simple-temporal-workflow/payment/activities"
+	paymentchild "simple-temporal-workflow/payment/child"
+	paymentworkflows "simple-temporal-workflow/payment/workflows"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Stand-ins for the child workflows, mocked per test through OnWorkflow
+func processPayment(ctx workflow.Context, req paymentworkflows.PaymentRequest) (string, error) {
+	return "", nil
+}
+
+func processOrder(ctx workflow.Context, req orderworkflows.OrderRequest) (string, error) {
+	return "", nil
+}
+
+func refundPayment(ctx workflow.Context, req paymentworkflows.RefundRequest) (bool, error) {
+	return false, nil
+}
+
+// blockingOrder holds the order until the checkout is cancelled
+func blockingOrder(ctx workflow.Context, req orderworkflows.OrderRequest) (string, error) {
+	return "", workflow.Sleep(ctx, 24*time.Hour)
+}
+
+// blockingPayment holds the payment until the checkout is cancelled
+func blockingPayment(ctx workflow.Context, req paymentworkflows.PaymentRequest) (string, error) {
+	return "", workflow.Sleep(ctx, 24*time.Hour)
+}
+
+// CheckoutTestSuite defines the test suite for Checkout workflow
+type CheckoutTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func TestCheckoutTestSuite(t *testing.T) {
+	suite.Run(t, new(CheckoutTestSuite))
+}
+
+var checkoutRequest = CheckoutRequest{OrderID: "order-123", PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}
+
+var expectedRefund = paymentworkflows.RefundRequest{
+	PaymentID: "payment-123",
+	RefundID:  "checkout-1-refund",
+	Amount:    common.NewMoney(9999, "USD"),
+	Reason:    "checkout failed",
+}
+
+// newEnv returns an environment running the checkout as checkout-1 with
+// the given order workflow standing in for ProcessOrder
+func (s *CheckoutTestSuite) newEnv(processOrder func(workflow.Context, orderworkflows.OrderRequest) (string, error)) *testsuite.TestWorkflowEnvironment {
+	env := s.NewTestWorkflowEnvironment()
+	env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: "checkout-1"})
+	env.RegisterWorkflowWithOptions(processPayment, workflow.RegisterOptions{Name: paymentchild.ProcessPaymentWorkflow})
+	env.RegisterWorkflowWithOptions(processOrder, workflow.RegisterOptions{Name: orderchild.ProcessOrderV2Workflow})
+	env.RegisterWorkflowWithOptions(refundPayment, workflow.RegisterOptions{Name: paymentchild.RefundPaymentWorkflow})
+	return env
+}
+
+// queryProgress returns the checkout progress
+func (s *CheckoutTestSuite) queryProgress(env *testsuite.TestWorkflowEnvironment) CheckoutProgress {
+	result, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress CheckoutProgress
+	s.NoError(result.Get(&progress))
+	return progress
+}
+
+func (s *CheckoutTestSuite) TestCheckout_Success() {
+	env := s.newEnv(processOrder)
+
+	env.OnWorkflow(paymentchild.ProcessPaymentWorkflow, mock.Anything, paymentworkflows.PaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}).Return("Payment payment-123 processed", nil)
+	env.OnWorkflow(orderchild.ProcessOrderV2Workflow, mock.Anything, orderworkflows.OrderRequest{OrderID: "order-123"}).Return("Order order-123 processed", nil)
+
+	env.ExecuteWorkflow(NewWorkflows(TaskQueues{}).Checkout, checkoutRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("Checkout completed for order order-123 and payment payment-123", result)
+
+	// The children are addressable by deterministic IDs derived from the checkout
+	progress := s.queryProgress(env)
+	s.Equal([]string{StepProcessPayment, StepProcessOrder}, progress.CompletedSteps)
+	s.Equal("checkout-1-process-payment", progress.Payment.WorkflowID)
+	s.NotEmpty(progress.Payment.RunID)
+	s.Equal("Payment payment-123 processed", progress.Payment.Result)
+	s.Equal("checkout-1-process-order", progress.Order.WorkflowID)
+	s.Equal("Order order-123 processed", progress.Order.Result)
+	s.Nil(progress.Refund)
+
+	env.AssertExpectations(s.T())
+}
+
+// Children run on the task queues of their domains, wherever the checkout runs
+func (s *CheckoutTestSuite) TestCheckout_ChildTaskQueues() {
+	taskQueues := map[string]string{}
+	recordTaskQueue := func(ctx workflow.Context) {
+		info := workflow.GetInfo(ctx)
+		taskQueues[info.WorkflowType.Name] = info.TaskQueueName
+	}
+	env := s.newEnv(func(ctx workflow.Context, req orderworkflows.OrderRequest) (string, error) {
+		recordTaskQueue(ctx)
+		return "", nil
+	})
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context, req paymentworkflows.PaymentRequest) (string, error) {
+		recordTaskQueue(ctx)
+		return "", nil
+	}, workflow.RegisterOptions{Name: paymentchild.ProcessPaymentWorkflow, DisableAlreadyRegisteredCheck: true})
+
+	env.ExecuteWorkflow(NewWorkflows(TaskQueues{Payment: "payments", Order: "orders"}).Checkout, checkoutRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	s.Equal(map[string]string{
+		paymentchild.ProcessPaymentWorkflow: "payments",
+		orderchild.ProcessOrderV2Workflow:   "orders",
+	}, taskQueues)
+}
+
+func (s *CheckoutTestSuite) TestCheckout_PaymentFailure() {
+	env := s.newEnv(processOrder)
+
+	env.OnWorkflow(paymentchild.ProcessPaymentWorkflow, mock.Anything, mock.Anything).Return("", errors.New("card declined"))
+
+	env.ExecuteWorkflow(NewWorkflows(TaskQueues{}).Checkout, checkoutRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to process payment")
+
+	// Nothing was charged, so the order is not started and nothing is refunded
+	progress := s.queryProgress(env)
+	s.Equal(StepProcessPayment, progress.CurrentStep)
+	s.Nil(progress.Order)
+	s.Nil(progress.Refund)
+
+	env.AssertExpectations(s.T())
+}
+
+func (s *CheckoutTestSuite) TestCheckout_OrderFailureRefunds() {
+	env := s.newEnv(processOrder)
+
+	env.OnWorkflow(paymentchild.ProcessPaymentWorkflow, mock.Anything, mock.Anything).Return("Payment payment-123 processed", nil)
+	env.OnWorkflow(orderchild.ProcessOrderV2Workflow, mock.Anything, mock.Anything).Return("", errors.New("out of stock"))
+	env.OnWorkflow(paymentchild.RefundPaymentWorkflow, mock.Anything, expectedRefund).Return(true, nil).Once()
+
+	env.ExecuteWorkflow(NewWorkflows(TaskQueues{}).Checkout, checkoutRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to process order")
+
+	// The refund does not take the workflow ID of refunds started through
+	// the API, which one in flight would hold
+	progress := s.queryProgress(env)
+	s.Equal([]string{StepProcessPayment, StepRefundPayment}, progress.CompletedSteps)
+	s.Equal("checkout-1-refund-payment", progress.Refund.WorkflowID)
+
+	env.AssertExpectations(s.T())
+}
+
+func (s *CheckoutTestSuite) TestCheckout_RefundFailure() {
+	env := s.newEnv(processOrder)
+
+	env.OnWorkflow(paymentchild.ProcessPaymentWorkflow, mock.Anything, mock.Anything).Return("Payment payment-123 processed", nil)
+	env.OnWorkflow(orderchild.ProcessOrderV2Workflow, mock.Anything, mock.Anything).Return("", errors.New("out of stock"))
+	env.OnWorkflow(paymentchild.RefundPaymentWorkflow, mock.Anything, expectedRefund).Return(false, errors.New("gateway unavailable"))
+
+	env.ExecuteWorkflow(NewWorkflows(TaskQueues{}).Checkout, checkoutRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to process order")
+	s.Contains(env.GetWorkflowError().Error(), "failed to refund payment payment-123")
+
+	progress := s.queryProgress(env)
+	s.Equal(StepRefundPayment, progress.CurrentStep)
+
+	env.AssertExpectations(s.T())
+}
+
+func (s *CheckoutTestSuite) TestCheckout_CancelledRefunds() {
+	env := s.newEnv(blockingOrder)
+
+	env.OnWorkflow(paymentchild.ProcessPaymentWorkflow, mock.Anything, mock.Anything).Return("Payment payment-123 processed", nil)
+	env.OnWorkflow(paymentchild.RefundPaymentWorkflow, mock.Anything, expectedRefund).Return(true, nil).Once()
+
+	// Cancel while the order is being fulfilled
+	env.RegisterDelayedCallback(func() {
+		progress := s.queryProgress(env)
+		s.Equal(StepProcessOrder, progress.CurrentStep)
+		env.CancelWorkflow()
+	}, time.Hour)
+
+	env.ExecuteWorkflow(NewWorkflows(TaskQueues{}).Checkout, checkoutRequest)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "canceled")
+
+	// The order child was cancelled and the charge refunded afterwards
+	progress := s.queryProgress(env)
+	s.Equal([]string{StepProcessPayment, StepRefundPayment}, progress.CompletedSteps)
+	s.NotEmpty(progress.Order.RunID)
+
+	env.AssertExpectations(s.T())
+}
+
+// A payment cancelled along with the checkout may already have charged, so
+// the charge is refunded
+func (s *CheckoutTestSuite) TestCheckout_CancelledDuringPaymentRefunds() {
+	testCases := []struct {
+		name      string
+		refundErr error
+	}{
+		{"charged", nil},
+		{"not charged", temporal.NewNonRetryableApplicationError("payment payment-123 has not been charged", paymentactivities.ErrTypePaymentNotCharged, nil)},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			env := s.NewTestWorkflowEnvironment()
+			env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: "checkout-1"})
+			env.RegisterWorkflowWithOptions(blockingPayment, workflow.RegisterOptions{Name: paymentchild.ProcessPaymentWorkflow})
+			env.RegisterWorkflowWithOptions(processOrder, workflow.RegisterOptions{Name: orderchild.ProcessOrderV2Workflow})
+			env.RegisterWorkflowWithOptions(refundPayment, workflow.RegisterOptions{Name: paymentchild.RefundPaymentWorkflow})
+			env.OnWorkflow(paymentchild.RefundPaymentWorkflow, mock.Anything, expectedRefund).Return(tc.refundErr == nil, tc.refundErr).Once()
+
+			env.RegisterDelayedCallback(func() {
+				s.Equal(StepProcessPayment, s.queryProgress(env).CurrentStep)
+				env.CancelWorkflow()
+			}, time.Hour)
+
+			env.ExecuteWorkflow(NewWorkflows(TaskQueues{}).Checkout, checkoutRequest)
+
+			s.True(env.IsWorkflowCompleted())
+			s.Error(env.GetWorkflowError())
+			s.Contains(env.GetWorkflowError().Error(), "failed to process payment")
+			s.NotContains(env.GetWorkflowError().Error(), "failed to refund")
+
+			progress := s.queryProgress(env)
+			s.Equal([]string{StepRefundPayment}, progress.CompletedSteps)
+			s.Nil(progress.Order)
+
+			env.AssertExpectations(s.T())
+		})
+	}
+}
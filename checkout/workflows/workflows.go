@@ -0,0 +1,35 @@
+package workflows
+
+import (
+	"simple-temporal-workflow/logging"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Workflows provides checkout workflow implementations. Checkout has no
+// activities of its own; it composes the payment and order workflows.
+type Workflows struct {
+	taskQueues TaskQueues
+}
+
+// TaskQueues names the task queues the payment and order domains are polled
+// on, where a checkout starts their children. An empty task queue starts
+// them on the checkout's own.
+type TaskQueues struct {
+	Payment string
+	Order   string
+}
+
+// NewWorkflows creates a new checkout workflows service
+func NewWorkflows(taskQueues TaskQueues) *Workflows {
+	return &Workflows{taskQueues: taskQueues}
+}
+
+
+// domainName tags the workflows' logs
+const domainName = "checkout"
+
+// logger returns the workflow's replay-safe logger, tagged with the domain
+func logger(ctx workflow.Context) log.Logger {
+	return logging.Workflow(ctx, domainName)
+}
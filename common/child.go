@@ -0,0 +1,135 @@
+package common
+
+import (
+	"strings"
+	"unicode"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// DefaultParentClosePolicy cancels children when their parent closes, so
+// cancelling a workflow also cancels the work it delegated
+const DefaultParentClosePolicy = enums.PARENT_CLOSE_POLICY_REQUEST_CANCEL
+
+// ChildOption overrides the defaults of a child workflow started with ExecuteChild
+type ChildOption func(*workflow.ChildWorkflowOptions)
+
+// WithChildWorkflowID replaces the derived child workflow ID, e.g. to share
+// an ID with workflows started through the API
+func WithChildWorkflowID(workflowID string) ChildOption {
+	return func(o *workflow.ChildWorkflowOptions) {
+		o.WorkflowID = workflowID
+	}
+}
+
+// WithParentClosePolicy sets what happens to the child when its parent closes
+func WithParentClosePolicy(policy enums.ParentClosePolicy) ChildOption {
+	return func(o *workflow.ChildWorkflowOptions) {
+		o.ParentClosePolicy = policy
+	}
+}
+
+// WithChildTaskQueue runs the child on another task queue than its parent,
+// typically that of the domain it belongs to. An empty taskQueue keeps the
+// parent's.
+func WithChildTaskQueue(taskQueue string) ChildOption {
+	return func(o *workflow.ChildWorkflowOptions) {
+		o.TaskQueue = taskQueue
+	}
+}
+
+// WithChildRetryPolicy retries the whole child workflow on failure
+func WithChildRetryPolicy(policy *temporal.RetryPolicy) ChildOption {
+	return func(o *workflow.ChildWorkflowOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithChildOptions applies arbitrary changes to the child workflow options
+func WithChildOptions(apply func(*workflow.ChildWorkflowOptions)) ChildOption {
+	return ChildOption(apply)
+}
+
+// ChildWorkflowID derives a deterministic child ID from the parent workflow
+// ID and the child's workflow type, e.g. "checkout-42-process-payment" for
+// ProcessPayment.v1. Parents starting the same type twice must tell the
+// children apart with WithChildWorkflowID.
+func ChildWorkflowID(ctx workflow.Context, workflowType string) string {
+	return workflow.GetInfo(ctx).WorkflowExecution.ID + "-" + childName(workflowType)
+}
+
+// ChildWorkflowOptions returns the options ExecuteChild starts a child with:
+// a derived workflow ID, DefaultParentClosePolicy and waiting for the child
+// to finish cancelling, followed by opts
+func ChildWorkflowOptions(ctx workflow.Context, workflowType string, opts ...ChildOption) workflow.ChildWorkflowOptions {
+	options := workflow.ChildWorkflowOptions{
+		WorkflowID:          ChildWorkflowID(ctx, workflowType),
+		ParentClosePolicy:   DefaultParentClosePolicy,
+		WaitForCancellation: true,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// ChildRun is a typed handle to a child workflow returning T
+type ChildRun[T any] struct {
+	workflowID string
+	future     workflow.ChildWorkflowFuture
+}
+
+// ExecuteChild starts a child workflow of workflowType with the options of
+// ChildWorkflowOptions. Domains wrap it in typed stubs, see payment/child.
+func ExecuteChild[T any](ctx workflow.Context, workflowType string, input any, opts ...ChildOption) *ChildRun[T] {
+	options := ChildWorkflowOptions(ctx, workflowType, opts...)
+	ctx = workflow.WithChildOptions(ctx, options)
+	return &ChildRun[T]{
+		workflowID: options.WorkflowID,
+		future:     workflow.ExecuteChildWorkflow(ctx, workflowType, input),
+	}
+}
+
+// WorkflowID returns the ID the child was started with
+func (r *ChildRun[T]) WorkflowID() string {
+	return r.workflowID
+}
+
+// Execution blocks until the child has started and returns its execution
+func (r *ChildRun[T]) Execution(ctx workflow.Context) (workflow.Execution, error) {
+	var execution workflow.Execution
+	err := r.future.GetChildWorkflowExecution().Get(ctx, &execution)
+	return execution, err
+}
+
+// Get blocks until the child completes and returns its result
+func (r *ChildRun[T]) Get(ctx workflow.Context) (T, error) {
+	var result T
+	err := r.future.Get(ctx, &result)
+	return result, err
+}
+
+// Future returns the underlying future, for use with selectors and signals
+func (r *ChildRun[T]) Future() workflow.ChildWorkflowFuture {
+	return r.future
+}
+
+// childName turns a versioned workflow type such as "ProcessPayment.v1" into
+// "process-payment"
+func childName(workflowType string) string {
+	name, _, _ := strings.Cut(workflowType, ".")
+
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func greet(ctx workflow.Context, name string) (string, error) {
+	return "hello " + name, nil
+}
+
+// newChildEnv returns an environment running parents as parent-1 with greet
+// registered as Greet.v1
+func newChildEnv() *testsuite.TestWorkflowEnvironment {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetStartWorkflowOptions(client.StartWorkflowOptions{ID: "parent-1"})
+	env.RegisterWorkflowWithOptions(greet, workflow.RegisterOptions{Name: "Greet.v1"})
+	return env
+}
+
+func TestExecuteChild(t *testing.T) {
+	env := newChildEnv()
+
+	var workflowID, runID string
+	parent := func(ctx workflow.Context) (string, error) {
+		run := ExecuteChild[string](ctx, "Greet.v1", "alice")
+		workflowID = run.WorkflowID()
+		execution, err := run.Execution(ctx)
+		if err != nil {
+			return "", err
+		}
+		runID = execution.RunID
+		return run.Get(ctx)
+	}
+
+	env.ExecuteWorkflow(parent)
+	require.NoError(t, env.GetWorkflowError())
+
+	var result string
+	require.NoError(t, env.GetWorkflowResult(&result))
+	assert.Equal(t, "hello alice", result)
+	assert.Equal(t, "parent-1-greet", workflowID)
+	assert.NotEmpty(t, runID)
+}
+
+func TestChildWorkflowOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ChildOption
+		expected workflow.ChildWorkflowOptions
+	}{
+		{
+			name: "defaults",
+			expected: workflow.ChildWorkflowOptions{
+				WorkflowID:          "parent-1-process-payment",
+				ParentClosePolicy:   enums.PARENT_CLOSE_POLICY_REQUEST_CANCEL,
+				WaitForCancellation: true,
+			},
+		},
+		{
+			name: "overrides",
+			opts: []ChildOption{
+				WithChildWorkflowID("refund-payment-1"),
+				WithParentClosePolicy(enums.PARENT_CLOSE_POLICY_ABANDON),
+				WithChildTaskQueue("payments"),
+				WithChildOptions(func(o *workflow.ChildWorkflowOptions) {
+					o.WaitForCancellation = false
+				}),
+			},
+			expected: workflow.ChildWorkflowOptions{
+				WorkflowID:        "refund-payment-1",
+				TaskQueue:         "payments",
+				ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newChildEnv()
+
+			var options workflow.ChildWorkflowOptions
+			env.ExecuteWorkflow(func(ctx workflow.Context) error {
+				options = ChildWorkflowOptions(ctx, "ProcessPayment.v1", tt.opts...)
+				return nil
+			})
+			require.NoError(t, env.GetWorkflowError())
+
+			assert.Equal(t, tt.expected, options)
+		})
+	}
+}
+
+func TestChildWorkflowOptions_TaskQueue(t *testing.T) {
+	env := newChildEnv()
+	var routed, parents workflow.ChildWorkflowOptions
+	env.ExecuteWorkflow(func(ctx workflow.Context) error {
+		routed = ChildWorkflowOptions(ctx, "RefundPayment.v1", WithChildTaskQueue("payments"))
+		parents = ChildWorkflowOptions(ctx, "RefundPayment.v1", WithChildTaskQueue(""))
+		return nil
+	})
+	require.NoError(t, env.GetWorkflowError())
+
+	assert.Equal(t, "payments", routed.TaskQueue)
+	// Children without a task queue run on their parent's
+	assert.Empty(t, parents.TaskQueue)
+}
@@ -2,16 +2,21 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
+	"github.com/google/uuid"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 )
 
 // WorkflowExecutionParams holds parameters for executing a workflow
 type WorkflowExecutionParams struct {
 	WorkflowType      string
 	WorkflowIDPrefix  string
+	WorkflowID        string // Fixed ID replacing the generated one, see ExecuteWorkflow
 	WorkflowInput     any
 	SearchAttributes  map[string]any // Flexible search attributes
 	SuccessMessage    string
@@ -22,6 +27,9 @@ type WorkflowResult struct {
 	WorkflowID string `json:"workflowId"`
 	RunID      string `json:"runId"`
 	Message    string `json:"message"`
+
+	// Replayed is set when an idempotent start matched an existing workflow
+	Replayed bool `json:"-"`
 }
 
 // Client provides common workflow execution functionality
@@ -38,24 +46,89 @@ func NewClient(temporalClient temporalclient.Client, taskQueue string) *Client {
 	}
 }
 
-// ExecuteWorkflow starts a workflow with the given parameters
+// ExecuteWorkflow starts a workflow with the given parameters. Workflows get
+// a unique ID of their WorkflowIDPrefix and a random suffix, unless the
+// request is idempotent or the ID is fixed.
+//
+// Workflows started with a fixed WorkflowID are serialized: the start fails
+// with WorkflowExecutionAlreadyStarted while another run holds the ID. An
+// idempotent start records its key in the memo instead, and a retry matching
+// the key of the latest run under the ID, running or closed, replays it.
 func (c *Client) ExecuteWorkflow(ctx context.Context, params WorkflowExecutionParams) (*WorkflowResult, error) {
-	// Generate unique workflow ID  
-	workflowID := fmt.Sprintf("%s-%d", params.WorkflowIDPrefix, time.Now().Unix())
+	// Generate unique workflow ID
+	workflowID := params.WorkflowIDPrefix + "-" + uuid.NewString()
+
+	if params.WorkflowID != "" {
+		workflowID = params.WorkflowID
+	}
 
-	// Setup workflow options
+	// Setup workflow options. By default the SDK returns the running
+	// workflow when the ID is taken, which would drop a second start
+	// without an error.
 	options := temporalclient.StartWorkflowOptions{
-		ID:        workflowID,
-		TaskQueue: c.taskQueue,
+		ID:                                       workflowID,
+		TaskQueue:                                c.taskQueue,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
+	}
+
+	// Derive a deterministic workflow ID for idempotent starts so replays
+	// resolve to the original execution, whether running or closed
+	idempotencyKey, idempotent := IdempotencyKeyFromContext(ctx)
+	if idempotent {
+		var scope string
+		if principal, ok := PrincipalFromContext(ctx); ok {
+			scope = principal.Subject
+		}
+		idempotencyKey = IdempotentWorkflowID(params.WorkflowIDPrefix, idempotencyKey, scope)
+	}
+	memo := map[string]any{}
+	switch {
+	case idempotent && params.WorkflowID == "":
+		options.ID = idempotencyKey
+		options.WorkflowIDReusePolicy = enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE
+	case idempotent:
+		if result, ok := c.replayFixed(ctx, workflowID, idempotencyKey, params.SuccessMessage); ok {
+			return result, nil
+		}
+		memo[IdempotencyMemoKey] = idempotencyKey
 	}
 
 	// Add search attributes if provided
-	if len(params.SearchAttributes) > 0 {
-		options.SearchAttributes = params.SearchAttributes
+	searchAttributes := make(map[string]any, len(params.SearchAttributes)+1)
+	for key, value := range params.SearchAttributes {
+		searchAttributes[key] = value
+	}
+
+	// Record the authenticated caller, if any
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		searchAttributes[PrincipalSearchAttribute] = principal.Subject
+		memo[PrincipalMemoKey] = principal
+	}
+	if len(memo) > 0 {
+		options.Memo = memo
+	}
+
+	if len(searchAttributes) > 0 {
+		options.SearchAttributes = searchAttributes
 	}
 
 	// Start workflow
 	run, err := c.temporalClient.ExecuteWorkflow(ctx, options, params.WorkflowType, params.WorkflowInput)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if idempotent && errors.As(err, &alreadyStarted) {
+		if params.WorkflowID == "" {
+			return &WorkflowResult{
+				WorkflowID: options.ID,
+				RunID:      alreadyStarted.RunId,
+				Message:    params.SuccessMessage,
+				Replayed:   true,
+			}, nil
+		}
+		// A concurrent retry may have started the run holding the ID
+		if result, ok := c.replayFixed(ctx, workflowID, idempotencyKey, params.SuccessMessage); ok {
+			return result, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to start %s workflow: %w", params.WorkflowType, err)
 	}
@@ -65,4 +138,56 @@ func (c *Client) ExecuteWorkflow(ctx context.Context, params WorkflowExecutionPa
 		RunID:      run.GetRunID(),
 		Message:    params.SuccessMessage,
 	}, nil
-}
\ No newline at end of file
+}
+
+// replayFixed returns the latest run of the fixed workflowID when it was
+// started with idempotencyKey
+func (c *Client) replayFixed(ctx context.Context, workflowID, idempotencyKey, message string) (*WorkflowResult, bool) {
+	description, err := c.temporalClient.DescribeWorkflowExecution(ctx, workflowID, "")
+	if err != nil {
+		// Without a previous run, or when it cannot be described, the
+		// start decides
+		return nil, false
+	}
+
+	info := description.GetWorkflowExecutionInfo()
+	payload, ok := info.GetMemo().GetFields()[IdempotencyMemoKey]
+	if !ok {
+		return nil, false
+	}
+	var key string
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &key); err != nil || key != idempotencyKey {
+		return nil, false
+	}
+
+	return &WorkflowResult{
+		WorkflowID: workflowID,
+		RunID:      info.GetExecution().GetRunId(),
+		Message:    message,
+		Replayed:   true,
+	}, true
+}
+
+// SignalWorkflow sends a signal to a workflow execution. An empty runID
+// targets the latest run of the workflow.
+func (c *Client) SignalWorkflow(ctx context.Context, workflowID, runID, signalName string, arg any) error {
+	if err := c.temporalClient.SignalWorkflow(ctx, workflowID, runID, signalName, arg); err != nil {
+		return fmt.Errorf("failed to signal %s to workflow %s: %w", signalName, workflowID, err)
+	}
+	return nil
+}
+
+// QueryWorkflow runs a query against a workflow execution and decodes the result.
+// An empty runID targets the latest run of the workflow.
+func (c *Client) QueryWorkflow(ctx context.Context, workflowID, runID, queryType string, result any) error {
+	value, err := c.temporalClient.QueryWorkflow(ctx, workflowID, runID, queryType)
+	if err != nil {
+		return fmt.Errorf("failed to query %s on workflow %s: %w", queryType, workflowID, err)
+	}
+
+	if err := value.Get(result); err != nil {
+		return fmt.Errorf("failed to decode %s query result: %w", queryType, err)
+	}
+
+	return nil
+}
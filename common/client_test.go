@@ -0,0 +1,197 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/mocks"
+)
+
+func TestClient_ExecuteWorkflowGeneratedID(t *testing.T) {
+	params := WorkflowExecutionParams{
+		WorkflowType:     "ProcessOrder.v1",
+		WorkflowIDPrefix: "process-order",
+		WorkflowInput:    "order-123",
+	}
+
+	// Starts in the same second get distinct IDs, and a taken ID fails
+	// rather than attaching to the running workflow
+	var ids []string
+	isUniqueStart := mock.MatchedBy(func(options temporalclient.StartWorkflowOptions) bool {
+		return strings.HasPrefix(options.ID, "process-order-") &&
+			options.WorkflowExecutionErrorWhenAlreadyStarted
+	})
+	temporalClient := &mocks.Client{}
+	temporalClient.On("ExecuteWorkflow", mock.Anything, isUniqueStart, "ProcessOrder.v1", "order-123").
+		Return(func(ctx context.Context, options temporalclient.StartWorkflowOptions, workflow interface{}, args ...interface{}) temporalclient.WorkflowRun {
+			ids = append(ids, options.ID)
+			run := &mocks.WorkflowRun{}
+			run.On("GetID").Return(options.ID)
+			run.On("GetRunID").Return("run-1")
+			return run
+		}, nil).Twice()
+
+	c := NewClient(temporalClient, "queue")
+	for i := 0; i < 2; i++ {
+		_, err := c.ExecuteWorkflow(context.Background(), params)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+	temporalClient.AssertExpectations(t)
+}
+
+func TestClient_ExecuteWorkflowIdempotent(t *testing.T) {
+	params := WorkflowExecutionParams{
+		WorkflowType:     "ProcessOrder.v1",
+		WorkflowIDPrefix: "process-order",
+		WorkflowInput:    "order-123",
+		SuccessMessage:   "started",
+	}
+	ctx := WithIdempotencyKey(context.Background(), "retry-key")
+	expectedID := IdempotentWorkflowID("process-order", "retry-key", "")
+
+	isIdempotentStart := mock.MatchedBy(func(options temporalclient.StartWorkflowOptions) bool {
+		return options.ID == expectedID &&
+			options.WorkflowIDReusePolicy == enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE &&
+			options.WorkflowExecutionErrorWhenAlreadyStarted
+	})
+
+	t.Run("first request starts workflow", func(t *testing.T) {
+		run := &mocks.WorkflowRun{}
+		run.On("GetID").Return(expectedID)
+		run.On("GetRunID").Return("run-1")
+		temporalClient := &mocks.Client{}
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isIdempotentStart, "ProcessOrder.v1", "order-123").Return(run, nil)
+
+		result, err := NewClient(temporalClient, "queue").ExecuteWorkflow(ctx, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedID, result.WorkflowID)
+		assert.Equal(t, "run-1", result.RunID)
+		assert.False(t, result.Replayed)
+	})
+
+	t.Run("retry replays original workflow", func(t *testing.T) {
+		temporalClient := &mocks.Client{}
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isIdempotentStart, "ProcessOrder.v1", "order-123").
+			Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "run-1"))
+
+		result, err := NewClient(temporalClient, "queue").ExecuteWorkflow(ctx, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, expectedID, result.WorkflowID)
+		assert.Equal(t, "run-1", result.RunID)
+		assert.Equal(t, "started", result.Message)
+		assert.True(t, result.Replayed)
+	})
+
+	t.Run("keys are scoped to the principal", func(t *testing.T) {
+		alice := IdempotentWorkflowID("process-order", "retry-key", "alice")
+		bob := IdempotentWorkflowID("process-order", "retry-key", "bob")
+
+		assert.NotEqual(t, alice, bob)
+		assert.Equal(t, alice, IdempotentWorkflowID("process-order", "retry-key", "alice"))
+	})
+}
+
+func TestClient_ExecuteWorkflowFixedID(t *testing.T) {
+	params := WorkflowExecutionParams{
+		WorkflowType:     "RefundPayment.v1",
+		WorkflowIDPrefix: "refund-payment",
+		WorkflowID:       "refund-payment-payment-123",
+		WorkflowInput:    "payment-123",
+		SuccessMessage:   "started",
+	}
+	ctx := WithIdempotencyKey(context.Background(), "retry-key")
+	recordedKey := IdempotentWorkflowID("refund-payment", "retry-key", "")
+
+	// The fixed ID is kept, and the key is recorded for retries to match
+	isFixedStart := mock.MatchedBy(func(options temporalclient.StartWorkflowOptions) bool {
+		return options.ID == "refund-payment-payment-123" &&
+			options.WorkflowIDReusePolicy == enums.WORKFLOW_ID_REUSE_POLICY_UNSPECIFIED &&
+			options.WorkflowExecutionErrorWhenAlreadyStarted &&
+			options.Memo[IdempotencyMemoKey] == recordedKey
+	})
+	describeRun := func(temporalClient *mocks.Client, key string) {
+		memo, err := converter.GetDefaultDataConverter().ToPayload(key)
+		require.NoError(t, err)
+		temporalClient.On("DescribeWorkflowExecution", mock.Anything, "refund-payment-payment-123", "").
+			Return(&workflowservice.DescribeWorkflowExecutionResponse{
+				WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+					Execution: &commonpb.WorkflowExecution{WorkflowId: "refund-payment-payment-123", RunId: "run-1"},
+					Memo:      &commonpb.Memo{Fields: map[string]*commonpb.Payload{IdempotencyMemoKey: memo}},
+				},
+			}, nil)
+	}
+
+	t.Run("uses the fixed ID", func(t *testing.T) {
+		run := &mocks.WorkflowRun{}
+		run.On("GetID").Return("refund-payment-payment-123")
+		run.On("GetRunID").Return("run-1")
+		temporalClient := &mocks.Client{}
+		temporalClient.On("DescribeWorkflowExecution", mock.Anything, "refund-payment-payment-123", "").
+			Return(nil, serviceerror.NewNotFound("workflow not found"))
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isFixedStart, "RefundPayment.v1", "payment-123").Return(run, nil)
+
+		result, err := NewClient(temporalClient, "queue").ExecuteWorkflow(ctx, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, "refund-payment-payment-123", result.WorkflowID)
+		assert.False(t, result.Replayed)
+	})
+
+	t.Run("running workflow rejects the start", func(t *testing.T) {
+		temporalClient := &mocks.Client{}
+		describeRun(temporalClient, IdempotentWorkflowID("refund-payment", "other-key", ""))
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isFixedStart, "RefundPayment.v1", "payment-123").
+			Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "run-1"))
+
+		_, err := NewClient(temporalClient, "queue").ExecuteWorkflow(ctx, params)
+
+		var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+		assert.ErrorAs(t, err, &alreadyStarted)
+	})
+
+	// The latest run carries the key of the retry, whether it is still
+	// running or already closed
+	t.Run("retry replays the run started with the key", func(t *testing.T) {
+		temporalClient := &mocks.Client{}
+		describeRun(temporalClient, recordedKey)
+
+		result, err := NewClient(temporalClient, "queue").ExecuteWorkflow(ctx, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, "refund-payment-payment-123", result.WorkflowID)
+		assert.Equal(t, "run-1", result.RunID)
+		assert.Equal(t, "started", result.Message)
+		assert.True(t, result.Replayed)
+		temporalClient.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("start without a key skips the lookup", func(t *testing.T) {
+		run := &mocks.WorkflowRun{}
+		run.On("GetID").Return("refund-payment-payment-123")
+		run.On("GetRunID").Return("run-2")
+		temporalClient := &mocks.Client{}
+		temporalClient.On("ExecuteWorkflow", mock.Anything, mock.Anything, "RefundPayment.v1", "payment-123").Return(run, nil)
+
+		result, err := NewClient(temporalClient, "queue").ExecuteWorkflow(context.Background(), params)
+
+		require.NoError(t, err)
+		assert.Equal(t, "run-2", result.RunID)
+		temporalClient.AssertNotCalled(t, "DescribeWorkflowExecution", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// MaxIdempotencyKeyLength bounds the size of client supplied idempotency keys
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyMemoKey is the memo key recording the idempotency key of
+// workflows started under a fixed ID, see Client.ExecuteWorkflow
+const IdempotencyMemoKey = "idempotencyKey"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context whose workflow starts are deduplicated by key
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key carried by ctx, if any
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// IdempotentWorkflowID derives a deterministic workflow ID from an idempotency
+// key. Keys are scoped to the caller so two principals reusing the same key
+// never collide.
+func IdempotentWorkflowID(prefix, key, scope string) string {
+	sum := sha256.Sum256([]byte(scope + "\x00" + key))
+	return fmt.Sprintf("%s-%s", prefix, hex.EncodeToString(sum[:12]))
+}
+
+// ActivityIdempotencyKey derives a key that stays the same across retries of
+// the running activity, for deduplicating side effects in external systems.
+// It combines the workflow ID and activity ID with the run ID, as workflow IDs
+// such as per-payment refund IDs are reused by later runs. Outside an
+// activity it returns "", leaving the call undeduplicated.
+func ActivityIdempotencyKey(ctx context.Context) string {
+	if !activity.IsActivity(ctx) {
+		return ""
+	}
+
+	info := activity.GetInfo(ctx)
+	return fmt.Sprintf("%s/%s/%s", info.WorkflowExecution.ID, info.WorkflowExecution.RunID, info.ActivityID)
+}
@@ -0,0 +1,38 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestActivityIdempotencyKey(t *testing.T) {
+	t.Run("derived from the activity", func(t *testing.T) {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestActivityEnvironment()
+		keyActivity := func(ctx context.Context) (string, error) {
+			return ActivityIdempotencyKey(ctx), nil
+		}
+		env.RegisterActivity(keyActivity)
+
+		var keys []string
+		for i := 0; i < 2; i++ {
+			value, err := env.ExecuteActivity(keyActivity)
+			require.NoError(t, err)
+			var key string
+			require.NoError(t, value.Get(&key))
+			keys = append(keys, key)
+		}
+
+		assert.Equal(t, "default-test-workflow-id/default-test-run-id/0", keys[0])
+		// Each scheduled activity gets its own key, retries of one keep it
+		assert.NotEqual(t, keys[0], keys[1])
+	})
+
+	t.Run("empty outside an activity", func(t *testing.T) {
+		assert.Empty(t, ActivityIdempotencyKey(context.Background()))
+	})
+}
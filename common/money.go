@@ -0,0 +1,204 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is assumed when decoding legacy float amounts
+const DefaultCurrency = "USD"
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// currencyExponents holds the number of minor unit digits of supported ISO 4217 currencies
+var currencyExponents = map[string]int{
+	"AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "DKK": 2,
+	"EUR": 2, "GBP": 2, "HKD": 2, "INR": 2, "JPY": 0, "KRW": 0, "KWD": 3,
+	"MXN": 2, "NOK": 2, "NZD": 2, "SEK": 2, "SGD": 2, "USD": 2,
+}
+
+// Money is an amount in integer minor units (e.g. cents) of an ISO 4217 currency
+type Money struct {
+	MinorUnits int64  `json:"minorUnits"`
+	Currency   string `json:"currency"`
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{MinorUnits: minorUnits, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses a decimal amount in major units such as "99.99". Only a
+// single leading "-" is accepted as a sign.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	negative := strings.HasPrefix(amount, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(amount, "-"), ".")
+	if !isDigits(whole) || (fraction != "" && !isDigits(fraction)) || len(fraction) > exponent {
+		return Money{}, fmt.Errorf("invalid %s amount %q", currency, amount)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	minorUnits, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid %s amount %q", currency, amount)
+	}
+	if negative {
+		minorUnits = -minorUnits
+	}
+
+	return Money{MinorUnits: minorUnits, Currency: currency}, nil
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MoneyFromMajor converts a float amount in major units, rounding to the
+// nearest minor unit. Only use it at boundaries accepting legacy amounts.
+func MoneyFromMajor(amount float64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency %q", currency)
+	}
+
+	minorUnits := math.Round(amount * math.Pow10(exponent))
+	if math.IsNaN(minorUnits) || math.Abs(minorUnits) > math.MaxInt64/2 {
+		return Money{}, fmt.Errorf("amount %v out of range", amount)
+	}
+
+	return Money{MinorUnits: int64(minorUnits), Currency: currency}, nil
+}
+
+// CurrencyExponent returns the number of minor unit digits of a currency
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: cannot add %s to %s", ErrCurrencyMismatch, other.Currency, m.Currency)
+	}
+	return Money{MinorUnits: m.MinorUnits + other.MinorUnits, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: cannot subtract %s from %s", ErrCurrencyMismatch, other.Currency, m.Currency)
+	}
+	return Money{MinorUnits: m.MinorUnits - other.MinorUnits, Currency: m.Currency}, nil
+}
+
+// Cmp compares m with other, returning -1, 0 or +1
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, fmt.Errorf("%w: cannot compare %s with %s", ErrCurrencyMismatch, other.Currency, m.Currency)
+	}
+	switch {
+	case m.MinorUnits < other.MinorUnits:
+		return -1, nil
+	case m.MinorUnits > other.MinorUnits:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.MinorUnits == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.MinorUnits > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.MinorUnits < 0
+}
+
+// Validate checks the currency is a supported ISO 4217 code
+func (m Money) Validate() error {
+	if _, ok := CurrencyExponent(m.Currency); !ok {
+		return fmt.Errorf("unsupported currency %q", m.Currency)
+	}
+	return nil
+}
+
+// String formats the amount in major units, e.g. "99.99 USD"
+func (m Money) String() string {
+	exponent, ok := CurrencyExponent(m.Currency)
+	if !ok {
+		exponent = 2
+	}
+
+	sign := ""
+	minorUnits := m.MinorUnits
+	if minorUnits < 0 {
+		sign = "-"
+		minorUnits = -minorUnits
+	}
+
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d %s", sign, minorUnits, m.Currency)
+	}
+	divisor := int64(math.Pow10(exponent))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, minorUnits/divisor, exponent, minorUnits%divisor, m.Currency)
+}
+
+// UnmarshalJSON decodes {"minorUnits":9999,"currency":"USD"} as well as the
+// legacy bare float amount in major units, which is assumed to be in USD
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] != '{' {
+		var amount float64
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return fmt.Errorf("invalid amount: %w", err)
+		}
+		money, err := MoneyFromMajor(amount, DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = money
+		return nil
+	}
+
+	// Alias drops the UnmarshalJSON method to avoid recursion
+	type money Money
+	var decoded money
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = NewMoney(decoded.MinorUnits, decoded.Currency)
+	return nil
+}
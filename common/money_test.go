@@ -0,0 +1,109 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		amount   string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{"99.99", "USD", NewMoney(9999, "USD"), false},
+		{"99.9", "usd", NewMoney(9990, "USD"), false},
+		{"-10", "EUR", NewMoney(-1000, "EUR"), false},
+		{"500", "JPY", NewMoney(500, "JPY"), false},
+		{"1.234", "KWD", NewMoney(1234, "KWD"), false},
+		{"1.999", "USD", Money{}, true},
+		{"5.5", "JPY", Money{}, true},
+		{"abc", "USD", Money{}, true},
+		{"10", "XYZ", Money{}, true},
+		{"--5.00", "USD", Money{}, true},
+		{"-+5.00", "USD", Money{}, true},
+		{"+5.00", "USD", Money{}, true},
+		{"-", "USD", Money{}, true},
+		{"5.-1", "USD", Money{}, true},
+		{" 5.00", "USD", Money{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.amount+" "+tc.currency, func(t *testing.T) {
+			got, err := ParseMoney(tc.amount, tc.currency)
+
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a := NewMoney(1050, "USD")
+	b := NewMoney(250, "USD")
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(1300, "USD"), sum)
+
+	diff, err := b.Sub(a)
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(-800, "USD"), diff)
+	assert.True(t, diff.IsNegative())
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cmp)
+
+	_, err = a.Add(NewMoney(100, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = a.Cmp(NewMoney(100, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "99.99 USD", NewMoney(9999, "USD").String())
+	assert.Equal(t, "0.05 EUR", NewMoney(5, "EUR").String())
+	assert.Equal(t, "-10.50 USD", NewMoney(-1050, "USD").String())
+	assert.Equal(t, "500 JPY", NewMoney(500, "JPY").String())
+	assert.Equal(t, "1.234 KWD", NewMoney(1234, "KWD").String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		data, err := json.Marshal(NewMoney(9999, "USD"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"minorUnits":9999,"currency":"USD"}`, string(data))
+
+		var decoded Money
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, NewMoney(9999, "USD"), decoded)
+	})
+
+	t.Run("legacy float amount", func(t *testing.T) {
+		var decoded struct {
+			Amount Money `json:"amount"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(`{"amount":29.99}`), &decoded))
+		assert.Equal(t, NewMoney(2999, "USD"), decoded.Amount)
+	})
+
+	t.Run("lowercase currency", func(t *testing.T) {
+		var decoded Money
+		require.NoError(t, json.Unmarshal([]byte(`{"minorUnits":100,"currency":"eur"}`), &decoded))
+		assert.Equal(t, NewMoney(100, "EUR"), decoded)
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		var decoded Money
+		assert.Error(t, json.Unmarshal([]byte(`"ten dollars"`), &decoded))
+	})
+}
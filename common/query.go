@@ -0,0 +1,78 @@
+package common
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+// Query types registered by long-running workflows
+const (
+	QueryProgress = "progress"
+	QueryState    = "state"
+)
+
+// Workflow states reported by the state query
+const (
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// Progress tracks the step-by-step execution of a workflow for query handlers
+type Progress struct {
+	CurrentStep    string   `json:"currentStep"`
+	CompletedSteps []string `json:"completedSteps"`
+	LastError      string   `json:"lastError,omitempty"`
+}
+
+// WorkflowState is the compact status returned by the state query
+type WorkflowState struct {
+	Status      string `json:"status"`
+	CurrentStep string `json:"currentStep"`
+}
+
+// StartStep marks step as the one currently executing
+func (p *Progress) StartStep(step string) {
+	p.CurrentStep = step
+}
+
+// CompleteStep records the current step as finished
+func (p *Progress) CompleteStep() {
+	if p.CurrentStep == "" {
+		return
+	}
+	p.CompletedSteps = append(p.CompletedSteps, p.CurrentStep)
+	p.CurrentStep = ""
+}
+
+// Fail records err as the last error seen by the workflow
+func (p *Progress) Fail(err error) {
+	if err != nil {
+		p.LastError = err.Error()
+	}
+}
+
+// State derives the compact workflow state from the progress
+func (p *Progress) State() WorkflowState {
+	switch {
+	case p.LastError != "":
+		return WorkflowState{Status: StateFailed, CurrentStep: p.CurrentStep}
+	case p.CurrentStep == "" && len(p.CompletedSteps) > 0:
+		return WorkflowState{Status: StateCompleted}
+	default:
+		return WorkflowState{Status: StateRunning, CurrentStep: p.CurrentStep}
+	}
+}
+
+// SetProgressQueryHandlers registers the progress and state query handlers.
+// progress must return the domain specific progress struct and state the
+// compact workflow state derived from it.
+func SetProgressQueryHandlers[T any](ctx workflow.Context, progress func() T, state func() WorkflowState) error {
+	if err := workflow.SetQueryHandler(ctx, QueryProgress, func() (T, error) {
+		return progress(), nil
+	}); err != nil {
+		return err
+	}
+	return workflow.SetQueryHandler(ctx, QueryState, func() (WorkflowState, error) {
+		return state(), nil
+	})
+}
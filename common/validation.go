@@ -0,0 +1,346 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Violation describes a single field that failed validation
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects the field level violations of a request
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("validation failed: %s", e.Violations[0].Message)
+	}
+	return fmt.Sprintf("validation failed: %s (and %d more violations)", e.Violations[0].Message, len(e.Violations)-1)
+}
+
+// selfValidator is implemented by value types that know how to validate themselves
+type selfValidator interface {
+	Validate() error
+}
+
+// ruleCache holds the compiled rules of struct types, keyed by type
+var ruleCache sync.Map
+
+// Validate checks v against the rules declared in its `validate` struct tags
+// and returns a *ValidationError listing every violation.
+//
+// Supported rules, separated by commas:
+//
+//	required      value must not be the zero value
+//	positive      number, or value with an IsPositive method such as Money, must be greater than zero
+//	min=N, max=N  bounds on numbers, or on the length of strings, slices and maps
+//	enum=a|b|c    string value must be one of the listed values
+//	regex=EXPR    string value must match EXPR; must be the last rule as EXPR may contain commas
+//
+// Rules other than required are skipped for empty values. Fields whose type
+// has a Validate() error method are checked with it, other nested structs are
+// validated recursively. Violations are reported using JSON field paths.
+//
+// The rules of a type are compiled on first use, see CompileValidation. A
+// malformed or unknown rule fails validation with an error other than
+// *ValidationError.
+func Validate(v any) error {
+	var violations []Violation
+	if err := validateValue(reflect.ValueOf(v), "", &violations); err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// CompileValidation compiles the rules of v's struct type and of the structs
+// nested in it, returning an error for malformed or unknown rules. Call it
+// when registering the type, e.g. as a request body, so that a bad tag fails
+// at startup rather than on the first request.
+func CompileValidation(v any) error {
+	_, err := compiledRules(reflect.TypeOf(v))
+	return err
+}
+
+// typeRules are the compiled rules of each field of a struct type
+type typeRules struct {
+	fields [][]rule
+	err    error
+}
+
+// compiledRules returns the rules of a struct type, or of the struct a
+// pointer type points to, compiling and caching them on first use
+func compiledRules(t reflect.Type) ([][]rule, error) {
+	t = structType(t)
+	if t == nil {
+		return nil, nil
+	}
+	cached, ok := ruleCache.Load(t)
+	if !ok {
+		cached, _ = ruleCache.LoadOrStore(t, compileType(t, map[reflect.Type]bool{}))
+	}
+	return cached.(*typeRules).fields, cached.(*typeRules).err
+}
+
+// compileType compiles the rules of a struct type and checks those of the
+// structs nested in it, skipping the types being compiled by its callers
+func compileType(t reflect.Type, visiting map[reflect.Type]bool) *typeRules {
+	visiting[t] = true
+	compiled := &typeRules{fields: make([][]rule, t.NumField())}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var err error
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			compiled.fields[i], err = parseRules(tag)
+		}
+		if nested := structType(field.Type); err == nil && nested != nil && !visiting[nested] {
+			if cached, ok := ruleCache.Load(nested); ok {
+				err = cached.(*typeRules).err
+			} else {
+				err = compileType(nested, visiting).err
+			}
+		}
+		if err != nil {
+			compiled.err = fmt.Errorf("invalid validation rules of %s: field %s: %w", t, field.Name, err)
+			break
+		}
+	}
+	return compiled
+}
+
+// structType returns t, or the type t points to, when it is a struct
+func structType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+func validateValue(value reflect.Value, prefix string, violations *[]Violation) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	valueType := value.Type()
+	rules, err := compiledRules(valueType)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		fieldValue := value.Field(i)
+
+		for _, rule := range rules[i] {
+			if violation, ok := checkRule(fieldValue, name, rule); !ok {
+				*violations = append(*violations, violation)
+				// Later rules add noise once the value is known to be missing
+				if rule.name == "required" {
+					break
+				}
+			}
+		}
+
+		if validator, ok := fieldValue.Interface().(selfValidator); ok {
+			if !isEmpty(fieldValue) {
+				if err := validator.Validate(); err != nil {
+					*violations = append(*violations, Violation{Field: name, Rule: "valid", Message: fmt.Sprintf("%s is invalid: %v", name, err)})
+				}
+			}
+		} else if field.Anonymous {
+			err = validateValue(fieldValue, prefix, violations)
+		} else {
+			err = validateValue(fieldValue, name+".", violations)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldName returns the JSON name of a field, falling back to its Go name
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+type rule struct {
+	name    string
+	param   string
+	limit   float64        // Of min and max
+	pattern *regexp.Regexp // Of regex
+}
+
+// parseRules parses and checks the rules of a validate tag
+func parseRules(tag string) ([]rule, error) {
+	var rules []rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else if i := strings.Index(tag, ","); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		r := rule{name: name, param: param}
+		switch name {
+		case "required", "positive", "enum":
+		case "min", "max":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rule %q", name, param)
+			}
+			r.limit = limit
+		case "regex":
+			pattern, err := regexp.Compile(param)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex rule: %w", err)
+			}
+			r.pattern = pattern
+		default:
+			return nil, fmt.Errorf("unknown validation rule %q", name)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func checkRule(value reflect.Value, field string, r rule) (Violation, bool) {
+	violation := func(format string, args ...any) (Violation, bool) {
+		return Violation{Field: field, Rule: r.name, Message: fmt.Sprintf(field+" "+format, args...)}, false
+	}
+
+	if r.name == "required" {
+		if isEmpty(value) {
+			return violation("is required")
+		}
+		return Violation{}, true
+	}
+
+	// Optional values are only checked when present
+	if isEmpty(value) {
+		return Violation{}, true
+	}
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	switch r.name {
+	case "positive":
+		if signed, ok := value.Interface().(interface{ IsPositive() bool }); ok {
+			if !signed.IsPositive() {
+				return violation("must be positive")
+			}
+			return Violation{}, true
+		}
+		if actual, isLength, ok := magnitude(value); ok && !isLength && actual <= 0 {
+			return violation("must be positive")
+		}
+	case "min", "max":
+		limit := r.limit
+		actual, isLength, ok := magnitude(value)
+		if !ok {
+			return Violation{}, true
+		}
+		if r.name == "min" && actual < limit {
+			if isLength {
+				return violation("must be at least %s characters long", r.param)
+			}
+			return violation("must be at least %s", r.param)
+		}
+		if r.name == "max" && actual > limit {
+			if isLength {
+				return violation("must be at most %s characters long", r.param)
+			}
+			return violation("must be at most %s", r.param)
+		}
+	case "enum":
+		allowed := strings.Split(r.param, "|")
+		actual := fmt.Sprint(value.Interface())
+		for _, candidate := range allowed {
+			if actual == candidate {
+				return Violation{}, true
+			}
+		}
+		return violation("must be one of %s", strings.Join(allowed, ", "))
+	case "regex":
+		if value.Kind() == reflect.String && !r.pattern.MatchString(value.String()) {
+			return violation("must match %s", r.param)
+		}
+	}
+
+	return Violation{}, true
+}
+
+// magnitude returns the value compared by min and max rules
+func magnitude(value reflect.Value) (actual float64, isLength bool, ok bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(len([]rune(value.String()))), true, true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), false, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), false, true
+	}
+	return 0, false, false
+}
+
+func isEmpty(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	}
+	return value.IsZero()
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validationAddress struct {
+	Country string `json:"country" validate:"required,enum=US|CA|GB"`
+}
+
+type validationRequest struct {
+	ID       string             `json:"id" validate:"required,max=8"`
+	Quantity int                `json:"quantity" validate:"min=1,max=10"`
+	Amount   float64            `json:"amount" validate:"required,min=0.01"`
+	Code     string             `json:"code,omitempty" validate:"regex=^[A-Z]{2,3}(,[A-Z]{2,3})*$"`
+	Address  *validationAddress `json:"address"`
+	Total    Money              `json:"total,omitempty" validate:"positive"`
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		req        validationRequest
+		violations []Violation
+	}{
+		{
+			name: "valid request",
+			req:  validationRequest{ID: "order-1", Quantity: 2, Amount: 9.99, Code: "AB,CDE", Address: &validationAddress{Country: "CA"}},
+		},
+		{
+			name: "optional fields omitted",
+			req:  validationRequest{ID: "order-1", Amount: 0.01},
+		},
+		{
+			name: "missing required fields",
+			req:  validationRequest{},
+			violations: []Violation{
+				{Field: "id", Rule: "required", Message: "id is required"},
+				{Field: "amount", Rule: "required", Message: "amount is required"},
+			},
+		},
+		{
+			name: "out of range values",
+			req:  validationRequest{ID: "order-123456", Quantity: 11, Amount: 0.001},
+			violations: []Violation{
+				{Field: "id", Rule: "max", Message: "id must be at most 8 characters long"},
+				{Field: "quantity", Rule: "max", Message: "quantity must be at most 10"},
+				{Field: "amount", Rule: "min", Message: "amount must be at least 0.01"},
+			},
+		},
+		{
+			name: "pattern and enum mismatch",
+			req:  validationRequest{ID: "order-1", Amount: 1, Code: "ab", Address: &validationAddress{Country: "FR"}},
+			violations: []Violation{
+				{Field: "code", Rule: "regex", Message: "code must match ^[A-Z]{2,3}(,[A-Z]{2,3})*$"},
+				{Field: "address.country", Rule: "enum", Message: "address.country must be one of US, CA, GB"},
+			},
+		},
+		{
+			name: "invalid money",
+			req:  validationRequest{ID: "order-1", Amount: 1, Total: NewMoney(-100, "XYZ")},
+			violations: []Violation{
+				{Field: "total", Rule: "positive", Message: "total must be positive"},
+				{Field: "total", Rule: "valid", Message: `total is invalid: unsupported currency "XYZ"`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.req)
+
+			if tc.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tc.violations, validationErr.Violations)
+		})
+	}
+}
+
+func TestCompileValidation(t *testing.T) {
+	type unknownRule struct {
+		ID string `json:"id" validate:"requird"`
+	}
+	type badLimit struct {
+		Quantity int `json:"quantity" validate:"min=one"`
+	}
+	type badRegex struct {
+		Code string `json:"code" validate:"regex=[A-Z"`
+	}
+	type nestedBadRule struct {
+		Items []string  `json:"items" validate:"max=3"`
+		Limit *badLimit `json:"limit"`
+	}
+
+	require.NoError(t, CompileValidation(validationRequest{}))
+	require.NoError(t, CompileValidation(&validationRequest{}))
+
+	assert.EqualError(t, CompileValidation(unknownRule{}), `invalid validation rules of common.unknownRule: field ID: unknown validation rule "requird"`)
+	assert.EqualError(t, CompileValidation(badLimit{}), `invalid validation rules of common.badLimit: field Quantity: invalid min rule "one"`)
+	assert.ErrorContains(t, CompileValidation(badRegex{}), "field Code: invalid regex rule")
+	assert.ErrorContains(t, CompileValidation(&nestedBadRule{}), `field Limit: invalid validation rules of common.badLimit`)
+
+	// Validation reports the malformed rule instead of panicking
+	err := Validate(unknownRule{ID: "order-1"})
+	require.Error(t, err)
+	var validationErr *ValidationError
+	assert.False(t, errors.As(err, &validationErr))
+}
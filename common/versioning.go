@@ -0,0 +1,48 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// WorkflowType returns the registered type of a workflow version, e.g.
+// "ProcessOrder.v2"
+func WorkflowType(name string, version int) string {
+	return fmt.Sprintf("%s.v%d", name, version)
+}
+
+// RegisterWorkflowVersions registers every implementation of a workflow side
+// by side under its versioned type. Executions keep running on the version
+// they were started with, so a version may only be removed once none of its
+// executions are open; which version new executions start is up to the
+// client.
+func RegisterWorkflowVersions(w worker.WorkflowRegistry, name string, versions map[int]any) {
+	numbers := make([]int, 0, len(versions))
+	for version := range versions {
+		numbers = append(numbers, version)
+	}
+	sort.Ints(numbers)
+
+	for _, version := range numbers {
+		w.RegisterWorkflowWithOptions(versions[version], workflow.RegisterOptions{
+			Name: WorkflowType(name, version),
+		})
+	}
+}
+
+// PatchVersion wraps workflow.GetVersion for patching a workflow in place.
+// Executions that passed the change point before the patch was deployed get
+// workflow.DefaultVersion and must keep taking the old code path; all others
+// get maxSupported.
+func PatchVersion(ctx workflow.Context, changeID string, maxSupported workflow.Version) workflow.Version {
+	return workflow.GetVersion(ctx, changeID, workflow.DefaultVersion, maxSupported)
+}
+
+// Patched reports whether the execution runs the patched code of a single
+// change, i.e. whether it reached changeID after the patch was deployed
+func Patched(ctx workflow.Context, changeID string) bool {
+	return PatchVersion(ctx, changeID, 1) >= 1
+}
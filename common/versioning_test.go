@@ -0,0 +1,66 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// recordingRegistry records the names workflows are registered under
+type recordingRegistry struct {
+	names []string
+}
+
+func (r *recordingRegistry) RegisterWorkflow(w interface{}) {}
+
+func (r *recordingRegistry) RegisterWorkflowWithOptions(w interface{}, options workflow.RegisterOptions) {
+	r.names = append(r.names, options.Name)
+}
+
+func TestWorkflowType(t *testing.T) {
+	assert.Equal(t, "ProcessOrder.v2", WorkflowType("ProcessOrder", 2))
+}
+
+func TestRegisterWorkflowVersions(t *testing.T) {
+	registry := &recordingRegistry{}
+	RegisterWorkflowVersions(registry, "ProcessOrder", map[int]any{
+		2: greet,
+		1: greet,
+	})
+
+	assert.Equal(t, []string{"ProcessOrder.v1", "ProcessOrder.v2"}, registry.names)
+}
+
+func TestPatched(t *testing.T) {
+	patched := func(ctx workflow.Context) (bool, error) {
+		return Patched(ctx, "change-1"), nil
+	}
+
+	t.Run("new executions take the patch", func(t *testing.T) {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+
+		env.ExecuteWorkflow(patched)
+		require.NoError(t, env.GetWorkflowError())
+
+		var result bool
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.True(t, result)
+	})
+
+	t.Run("executions from before the patch keep the old path", func(t *testing.T) {
+		var suite testsuite.WorkflowTestSuite
+		env := suite.NewTestWorkflowEnvironment()
+		env.OnGetVersion("change-1", workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+
+		env.ExecuteWorkflow(patched)
+		require.NoError(t, env.GetWorkflowError())
+
+		var result bool
+		require.NoError(t, env.GetWorkflowResult(&result))
+		assert.False(t, result)
+	})
+}
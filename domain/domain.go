@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"simple-temporal-workflow/api"
+	"simple-temporal-workflow/metrics"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Domain is a business capability plugged into the service. Domains add
+// themselves to the registry from an init function, so wiring a new one
+// into the worker, API server and health checks is a single import.
+type Domain interface {
+	// Name returns the unique domain name, e.g. "order"
+	Name() string
+
+	// Register registers the domain's workflows and activities with a worker
+	Register(w worker.Worker)
+
+	// NewClient creates the domain's workflow client on the shared Temporal client
+	NewClient(temporalClient client.Client, taskQueue string)
+
+	// Routes registers the domain's workflow-trigger endpoints
+	Routes(router *api.Router)
+}
+
+// TaskQueueUser is implemented by domains whose workflows start children in
+// other domains, which are started on the task queue of their own domain
+type TaskQueueUser interface {
+	SetTaskQueues(taskQueueOf func(domain string) string)
+}
+
+// HealthChecker is implemented by domains with dependencies of their own to check
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+var (
+	mu      sync.RWMutex
+	domains = make(map[string]Domain)
+)
+
+// Register adds a domain to the registry, panicking on duplicate names
+func Register(d Domain) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := domains[d.Name()]; exists {
+		panic(fmt.Sprintf("domain %s registered twice", d.Name()))
+	}
+	domains[d.Name()] = d
+}
+
+// All returns every registered domain ordered by name
+func All() []Domain {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Domain, 0, len(domains))
+	for _, d := range domains {
+		all = append(all, d)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// Registration returns the worker registration of every domain: the worker
+// of each task queue registers the workflows and activities of the domains
+// taskQueueOf assigns to it. Domains starting children in other domains are
+// given taskQueueOf first, so the children run on their domain's task
+// queue wherever their parent runs.
+func Registration(taskQueueOf func(domain string) string) func(taskQueue string, w worker.Worker) {
+	all := All()
+	for _, d := range all {
+		if user, ok := d.(TaskQueueUser); ok {
+			user.SetTaskQueues(taskQueueOf)
+		}
+	}
+
+	return func(taskQueue string, w worker.Worker) {
+		for _, d := range all {
+			if taskQueueOf(d.Name()) == taskQueue {
+				d.Register(w)
+			}
+		}
+	}
+}
+
+// NewClients creates the workflow client of every domain on the task queue
+// taskQueueOf assigns to it, counting the workflows each one starts in the
+// default metrics registry
+func NewClients(temporalClient client.Client, taskQueueOf func(domain string) string) {
+	for _, d := range All() {
+		d.NewClient(metrics.InstrumentClient(metrics.Default, temporalClient, d.Name()), taskQueueOf(d.Name()))
+	}
+}
+
+// RouteProviders returns every domain as an API route provider
+func RouteProviders() []api.RouteProvider {
+	all := All()
+	providers := make([]api.RouteProvider, len(all))
+	for i, d := range all {
+		providers[i] = d
+	}
+	return providers
+}
@@ -0,0 +1,9 @@
+package main
+
+// Domains served by this process. Each one registers itself with the domain
+// registry when imported.
+import (
+	_ "simple-temporal-workflow/checkout"
+	_ "simple-temporal-workflow/order"
+	_ "simple-temporal-workflow/payment"
+)
@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Domain is the key of the domain in workflow and activity logs
+const Domain = "Domain"
+
+// Activity returns the logger of the running activity tagged with its
+// domain. The SDK already tags it with the workflow ID, run ID, activity ID
+// and attempt. Outside an activity, as when activities are called directly
+// in tests, it logs through the default slog logger.
+func Activity(ctx context.Context, domain string) log.Logger {
+	if !activity.IsActivity(ctx) {
+		return log.With(log.NewStructuredLogger(slog.Default()), Domain, domain)
+	}
+	return log.With(activity.GetLogger(ctx), Domain, domain)
+}
+
+// Workflow returns the workflow's replay-safe logger tagged with its domain.
+// The SDK already tags it with the workflow ID, run ID and attempt.
+func Workflow(ctx workflow.Context, domain string) log.Logger {
+	return log.With(workflow.GetLogger(ctx), Domain, domain)
+}
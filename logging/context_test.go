@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// records decodes the JSON log records in buf, keyed by message
+func records(t *testing.T, buf *bytes.Buffer) map[string]map[string]any {
+	byMessage := make(map[string]map[string]any)
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]any
+		require.NoError(t, decoder.Decode(&record))
+		byMessage[record["msg"].(string)] = record
+	}
+	return byMessage
+}
+
+func newSuite(buf *bytes.Buffer) *testsuite.WorkflowTestSuite {
+	var suite testsuite.WorkflowTestSuite
+	suite.SetLogger(log.NewStructuredLogger(slog.New(slog.NewJSONHandler(buf, nil))))
+	return &suite
+}
+
+func TestActivity(t *testing.T) {
+	var buf bytes.Buffer
+	env := newSuite(&buf).NewTestActivityEnvironment()
+	logActivity := func(ctx context.Context) error {
+		Activity(ctx, "order").Info("Reserving inventory", "OrderID", "order-123")
+		return nil
+	}
+	env.RegisterActivity(logActivity)
+
+	_, err := env.ExecuteActivity(logActivity)
+	require.NoError(t, err)
+
+	record := records(t, &buf)["Reserving inventory"]
+	require.NotNil(t, record)
+	assert.Equal(t, "order", record[Domain])
+	assert.Equal(t, "order-123", record["OrderID"])
+	assert.Equal(t, "default-test-workflow-id", record["WorkflowID"])
+	assert.Equal(t, "default-test-run-id", record["RunID"])
+	assert.Contains(t, record, "ActivityID")
+	assert.EqualValues(t, 1, record["Attempt"])
+}
+
+func TestActivity_OutsideActivity(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	Activity(context.Background(), "payment").Info("Charging payment")
+
+	record := records(t, &buf)["Charging payment"]
+	require.NotNil(t, record)
+	assert.Equal(t, "payment", record[Domain])
+}
+
+func TestWorkflow(t *testing.T) {
+	var buf bytes.Buffer
+	env := newSuite(&buf).NewTestWorkflowEnvironment()
+	logWorkflow := func(ctx workflow.Context) error {
+		Workflow(ctx, "checkout").Warn("Refunding checkout payment", "PaymentID", "payment-123")
+		return workflow.Sleep(ctx, time.Second)
+	}
+	env.RegisterWorkflow(logWorkflow)
+
+	env.ExecuteWorkflow(logWorkflow)
+	require.NoError(t, env.GetWorkflowError())
+
+	record := records(t, &buf)["Refunding checkout payment"]
+	require.NotNil(t, record)
+	assert.Equal(t, "checkout", record[Domain])
+	assert.Equal(t, "payment-123", record["PaymentID"])
+	// Workers tag workflow loggers with the workflow ID, run ID and attempt,
+	// the test environment does not
+}
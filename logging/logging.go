@@ -0,0 +1,70 @@
+// Package logging sets up the service's structured logger. The worker builds
+// one slog logger from its configuration and hands it to the Temporal SDK,
+// so the worker, the API server, workflows and activities all log through it
+// with the same level and format.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats selectable through LOG_FORMAT
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config selects the level and format of the logger
+type Config struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error
+	Format string `yaml:"format"` // FormatText or FormatJSON
+}
+
+// DefaultConfig logs at info level in the text format
+func DefaultConfig() Config {
+	return Config{Level: "info", Format: FormatText}
+}
+
+// Validate checks the level and format
+func (c Config) Validate() error {
+	if _, err := ParseLevel(c.Level); err != nil {
+		return err
+	}
+	switch strings.ToLower(c.Format) {
+	case "", FormatText, FormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown log format %q, expected %s or %s", c.Format, FormatText, FormatJSON)
+}
+
+// New creates a logger writing to w. Empty fields fall back to
+// DefaultConfig; unknown levels and formats are an error.
+func New(config Config, w io.Writer) (*slog.Logger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	level, _ := ParseLevel(config.Level)
+	options := &slog.HandlerOptions{Level: level}
+	if strings.ToLower(config.Format) == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, options)), nil
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN". An empty name
+// is the info level.
+func ParseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+	}
+	return level, nil
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(Config{Level: "info", Format: FormatJSON}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("Order processed", "OrderID", "order-123")
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "Order processed", record["msg"])
+		assert.Equal(t, "order-123", record["OrderID"])
+	})
+
+	t.Run("text by default", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(Config{}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("Order processed", "OrderID", "order-123")
+		assert.Contains(t, buf.String(), `level=INFO msg="Order processed" OrderID=order-123`)
+	})
+
+	t.Run("drops records below the level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(Config{Level: "WARN"}, &buf)
+		require.NoError(t, err)
+
+		logger.Info("Order processed")
+		assert.Empty(t, buf.String())
+		logger.Warn("Order failed")
+		assert.Contains(t, buf.String(), "Order failed")
+	})
+
+	t.Run("rejects unknown settings", func(t *testing.T) {
+		_, err := New(Config{Level: "verbose"}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, `unknown log level "verbose"`)
+
+		_, err = New(Config{Format: "xml"}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, `unknown log format "xml"`)
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"Warn":  slog.LevelWarn,
+		"ERROR": slog.LevelError,
+	} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+// Metrics recorded by the domain clients
+const (
+	WorkflowsStarted = "astral_workflows_started_total"
+)
+
+// Outcomes of a workflow start, reported in the status label
+const (
+	StatusStarted        = "started"
+	StatusAlreadyStarted = "already_started"
+	StatusFailed         = "failed"
+)
+
+// instrumentedClient counts the workflows a domain starts
+type instrumentedClient struct {
+	client.Client
+	started *prometheus.CounterVec
+	domain  string
+}
+
+// InstrumentClient returns a Temporal client recording every workflow
+// started through it in registry, labelled with domain
+func InstrumentClient(registry *Registry, temporalClient client.Client, domain string) client.Client {
+	started := Register(registry, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: WorkflowsStarted,
+		Help: "Workflow starts requested by the domain clients, by outcome.",
+	}, []string{"domain", "workflow_type", "status"}))
+	return &instrumentedClient{Client: temporalClient, started: started, domain: domain}
+}
+
+func (c *instrumentedClient) ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error) {
+	run, err := c.Client.ExecuteWorkflow(ctx, options, workflow, args...)
+
+	status := StatusStarted
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	switch {
+	case errors.As(err, &alreadyStarted):
+		status = StatusAlreadyStarted
+	case err != nil:
+		status = StatusFailed
+	}
+
+	c.started.WithLabelValues(c.domain, workflowTypeName(workflow), status).Inc()
+
+	return run, err
+}
+
+// workflowTypeName returns the type a workflow is started as, the name for
+// workflows started by name and the function name otherwise
+func workflowTypeName(workflow interface{}) string {
+	if name, ok := workflow.(string); ok {
+		return name
+	}
+	if fn := reflect.ValueOf(workflow); fn.Kind() == reflect.Func {
+		return runtime.FuncForPC(fn.Pointer()).Name()
+	}
+	return fmt.Sprintf("%T", workflow)
+}
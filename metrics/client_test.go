@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+// startClient fails ExecuteWorkflow with err
+type startClient struct {
+	client.Client
+	err error
+}
+
+func (c *startClient) ExecuteWorkflow(ctx context.Context, options client.StartWorkflowOptions, workflow interface{}, args ...interface{}) (client.WorkflowRun, error) {
+	return nil, c.err
+}
+
+func greet(ctx workflow.Context) error {
+	return nil
+}
+
+func TestInstrumentClient(t *testing.T) {
+	registry := NewRegistry()
+	start := func(err error, workflow interface{}) {
+		temporalClient := InstrumentClient(registry, &startClient{err: err}, "order")
+		temporalClient.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{}, workflow)
+	}
+
+	start(nil, "ProcessOrder.v2")
+	start(nil, "ProcessOrder.v2")
+	start(serviceerror.NewWorkflowExecutionAlreadyStarted("started", "", "run-1"), "ProcessOrder.v2")
+	start(errors.New("unavailable"), "CancelOrder.v1")
+	start(nil, greet)
+
+	exposition := scrape(t, registry)
+	assert.Contains(t, exposition, `# HELP astral_workflows_started_total`)
+	assert.Contains(t, exposition, `astral_workflows_started_total{domain="order",status="started",workflow_type="ProcessOrder.v2"} 2`)
+	assert.Contains(t, exposition, `astral_workflows_started_total{domain="order",status="already_started",workflow_type="ProcessOrder.v2"} 1`)
+	assert.Contains(t, exposition, `astral_workflows_started_total{domain="order",status="failed",workflow_type="CancelOrder.v1"} 1`)
+	assert.Contains(t, exposition, `astral_workflows_started_total{domain="order",status="started",workflow_type="simple-temporal-workflow/metrics.greet"} 1`)
+}
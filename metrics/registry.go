@@ -0,0 +1,52 @@
+// Package metrics collects the service's metrics in a Prometheus registry and
+// exposes them to scrapes. The worker, the API server, the domain clients and
+// the Temporal SDK all record into Default.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Default is the registry shared by the worker, the API server and the
+// domain clients, and exposed on the health server's /metrics endpoint
+var Default = NewRegistry()
+
+// DefaultBuckets are the histogram upper bounds in seconds, from 5ms to 60s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds every metric of the process
+type Registry struct {
+	*prometheus.Registry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{Registry: prometheus.NewRegistry()}
+}
+
+// Handler serves the registry's metrics to Prometheus scrapes
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.Registry, promhttp.HandlerOpts{})
+}
+
+// Register adds collector to registry, returning the collector registered
+// before it when several components share a metric, e.g. domain clients
+// counting their starts. A collector conflicting with another registered
+// under its name is a programming error.
+func Register[C prometheus.Collector](registry *Registry, collector C) C {
+	err := registry.Register(collector)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	if err != nil {
+		panic(err)
+	}
+	return collector
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrape returns the registry's metrics in the text exposition format
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	Register(registry, prometheus.NewGauge(prometheus.GaugeOpts{Name: "worker_running", Help: "Worker state."})).Set(1)
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Equal(t, "# HELP worker_running Worker state.\n# TYPE worker_running gauge\nworker_running 1\n", w.Body.String())
+}
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry()
+	opts := prometheus.CounterOpts{Name: "jobs_total", Help: "Jobs processed."}
+
+	// Components sharing a metric record into the first registered collector
+	first := Register(registry, prometheus.NewCounterVec(opts, []string{"queue"}))
+	second := Register(registry, prometheus.NewCounterVec(opts, []string{"queue"}))
+	first.WithLabelValues("orders").Inc()
+	second.WithLabelValues("orders").Inc()
+	assert.Contains(t, scrape(t, registry), `jobs_total{queue="orders"} 2`)
+
+	// A conflicting definition of the metric is a programming error
+	assert.Panics(t, func() {
+		Register(registry, prometheus.NewCounterVec(opts, []string{"queue", "result"}))
+	})
+}
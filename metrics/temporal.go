@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+)
+
+// temporalHandler records the SDK's metrics (task latencies, poll counts,
+// activity failures, ...) in a registry. Timers are recorded as histograms
+// in seconds.
+type temporalHandler struct {
+	metrics *temporalMetrics
+	tags    map[string]string
+}
+
+// temporalMetrics holds the vectors of the SDK's metrics. A metric is
+// labelled with the tags it is first recorded with; later tags outside that
+// set are dropped and missing ones are left empty, as Prometheus requires a
+// fixed label set per metric.
+type temporalMetrics struct {
+	registry *Registry
+	mu       sync.Mutex
+	vecs     map[string]*temporalVec
+}
+
+type temporalVec struct {
+	labels []string
+	vec    prometheus.Collector
+}
+
+// NewTemporalHandler returns a handler for client.Options.MetricsHandler
+// recording the SDK's metrics in registry
+func NewTemporalHandler(registry *Registry) client.MetricsHandler {
+	return &temporalHandler{metrics: &temporalMetrics{registry: registry, vecs: make(map[string]*temporalVec)}}
+}
+
+func (h *temporalHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for name, value := range h.tags {
+		merged[name] = value
+	}
+	for name, value := range tags {
+		merged[name] = value
+	}
+	return &temporalHandler{metrics: h.metrics, tags: merged}
+}
+
+func (h *temporalHandler) Counter(name string) client.MetricsCounter {
+	vec, values, ok := h.metrics.vec(name, h.tags, func(labels []string) prometheus.Collector {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: "Temporal SDK metric " + name + "."}, labels)
+	})
+	counter, isCounter := vec.(*prometheus.CounterVec)
+	if !ok || !isCounter {
+		return client.MetricsNopHandler.Counter(name)
+	}
+	return temporalCounter{counter.WithLabelValues(values...)}
+}
+
+func (h *temporalHandler) Gauge(name string) client.MetricsGauge {
+	vec, values, ok := h.metrics.vec(name, h.tags, func(labels []string) prometheus.Collector {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: "Temporal SDK metric " + name + "."}, labels)
+	})
+	gauge, isGauge := vec.(*prometheus.GaugeVec)
+	if !ok || !isGauge {
+		return client.MetricsNopHandler.Gauge(name)
+	}
+	return temporalGauge{gauge.WithLabelValues(values...)}
+}
+
+func (h *temporalHandler) Timer(name string) client.MetricsTimer {
+	vec, values, ok := h.metrics.vec(name, h.tags, func(labels []string) prometheus.Collector {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: "Temporal SDK metric " + name + ", in seconds.", Buckets: DefaultBuckets}, labels)
+	})
+	histogram, isHistogram := vec.(*prometheus.HistogramVec)
+	if !ok || !isHistogram {
+		return client.MetricsNopHandler.Timer(name)
+	}
+	return temporalTimer{histogram.WithLabelValues(values...)}
+}
+
+// vec returns the vector of name, registering it with the names of tags on
+// first use, and the label values tags map to. It reports false when the
+// vector cannot be registered, leaving the metric unrecorded.
+func (m *temporalMetrics) vec(name string, tags map[string]string, create func(labels []string) prometheus.Collector) (prometheus.Collector, []string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	v, ok := m.vecs[name]
+	if !ok {
+		labels := make([]string, 0, len(tags))
+		for label := range tags {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		collector := create(labels)
+		if err := m.registry.Register(collector); err != nil {
+			return nil, nil, false
+		}
+		v = &temporalVec{labels: labels, vec: collector}
+		m.vecs[name] = v
+	}
+
+	values := make([]string, len(v.labels))
+	for i, label := range v.labels {
+		values[i] = tags[label]
+	}
+	return v.vec, values, true
+}
+
+type temporalCounter struct{ counter prometheus.Counter }
+
+func (c temporalCounter) Inc(delta int64) {
+	if delta > 0 {
+		c.counter.Add(float64(delta))
+	}
+}
+
+type temporalGauge struct{ gauge prometheus.Gauge }
+
+func (g temporalGauge) Update(value float64) { g.gauge.Set(value) }
+
+type temporalTimer struct{ histogram prometheus.Observer }
+
+func (t temporalTimer) Record(d time.Duration) { t.histogram.Observe(d.Seconds()) }
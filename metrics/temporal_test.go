@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemporalHandler(t *testing.T) {
+	registry := NewRegistry()
+	handler := NewTemporalHandler(registry).WithTags(map[string]string{"namespace": "claude"})
+	activities := handler.WithTags(map[string]string{"activity_type": "ChargePayment"})
+
+	handler.Counter("temporal_request").Inc(2)
+	activities.Counter("temporal_activity_execution_failed").Inc(1)
+	handler.Gauge("temporal_num_pollers").Update(4)
+	activities.Timer("temporal_activity_execution_latency").Record(150 * time.Millisecond)
+
+	// A metric keeps the labels it was first recorded with
+	activities.Counter("temporal_request").Inc(1)
+	handler.WithTags(map[string]string{"namespace": "other"}).Gauge("temporal_num_pollers").Update(1)
+
+	// Names Prometheus rejects are not recorded
+	handler.Counter("temporal-invalid name").Inc(1)
+
+	exposition := scrape(t, registry)
+	assert.Contains(t, exposition, `temporal_request{namespace="claude"} 3`)
+	assert.Contains(t, exposition, `temporal_activity_execution_failed{activity_type="ChargePayment",namespace="claude"} 1`)
+	assert.Contains(t, exposition, `temporal_num_pollers{namespace="claude"} 4`)
+	assert.Contains(t, exposition, `temporal_num_pollers{namespace="other"} 1`)
+	assert.Contains(t, exposition, `temporal_activity_execution_latency_bucket{activity_type="ChargePayment",namespace="claude",le="0.25"} 1`)
+	assert.Contains(t, exposition, `temporal_activity_execution_latency_sum{activity_type="ChargePayment",namespace="claude"} 0.15`)
+	assert.NotContains(t, exposition, "invalid")
+}
@@ -1,12 +1,50 @@
 package activities
 
+import (
+	"context"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/logging"
+	"go.temporal.io/sdk/log"
+)
+
 // Activities provides order activity implementations
 type Activities struct {
 	// In a real implementation, these would be your database connections,
 	// external service clients, etc.
+	store *fulfillmentStore
 }
 
 // NewActivities creates a new order activities service
 func NewActivities() *Activities {
-	return &Activities{}
-}
\ No newline at end of file
+	return &Activities{
+		store: newFulfillmentStore(),
+	}
+}
+
+// domainName tags the activities' logs
+const domainName = "order"
+
+// logger returns the running activity's logger, tagged with the domain
+func logger(ctx context.Context) log.Logger {
+	return logging.Activity(ctx, domainName)
+}
+
+// idempotencyKey returns the key set on a request, falling back to one
+// derived from the running activity so retries reuse it
+func idempotencyKey(ctx context.Context, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return common.ActivityIdempotencyKey(ctx)
+}
+
+// subKey scopes an idempotency key to one of the calls sharing the
+// fulfillment store, so a key passed to several activities never returns
+// the ID issued by another
+func subKey(key, call string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "/" + call
+}
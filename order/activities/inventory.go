@@ -3,26 +3,53 @@ package activities
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type ReserveInventoryRequest struct {
-	OrderID string `json:"orderId"`
+	OrderID        string `json:"orderId"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
 }
 
 func (a *Activities) ReserveInventory(ctx context.Context, req ReserveInventoryRequest) (string, error) {
-	log.Printf("Reserving inventory for order: %s", req.OrderID)
+	logger(ctx).Info("Reserving inventory", "OrderID", req.OrderID)
+
+	key := subKey(idempotencyKey(ctx, req.IdempotencyKey), "reserve")
+	if reservationID, ok := a.store.lookup(key); ok {
+		logger(ctx).Info("Inventory already reserved", "OrderID", req.OrderID, "ReservationID", reservationID)
+		return reservationID, nil
+	}
 	
 	// Simulate inventory reservation
 	time.Sleep(200 * time.Millisecond)
 	
-	reservationID := fmt.Sprintf("res_%s_%s", req.OrderID, uuid.New().String()[:8])
+	reservationID := a.store.issue(key, func() string {
+		return fmt.Sprintf("res_%s_%s", req.OrderID, uuid.New().String()[:8])
+	})
 	
 	// In a real implementation, you'd update inventory tables, etc.
-	log.Printf("Inventory reserved with ID: %s", reservationID)
+	logger(ctx).Info("Inventory reserved", "OrderID", req.OrderID, "ReservationID", reservationID)
 	
 	return reservationID, nil
-}
\ No newline at end of file
+}
+
+type ReleaseInventoryRequest struct {
+	OrderID       string `json:"orderId"`
+	ReservationID string `json:"reservationId"`
+}
+
+// ReleaseInventory returns the stock held by a reservation. Releasing a
+// reservation twice is harmless, so retries need no idempotency key.
+func (a *Activities) ReleaseInventory(ctx context.Context, req ReleaseInventoryRequest) error {
+	logger(ctx).Info("Releasing inventory", "OrderID", req.OrderID, "ReservationID", req.ReservationID)
+
+	// Simulate inventory release
+	time.Sleep(100 * time.Millisecond)
+
+	// In a real implementation, you'd return the stock to inventory tables, etc.
+	logger(ctx).Info("Inventory released", "OrderID", req.OrderID, "ReservationID", req.ReservationID)
+
+	return nil
+}
@@ -33,4 +33,25 @@ func TestActivities_ProcessShipping(t *testing.T) {
 		assert.NoError(t, err2)
 		assert.NotEqual(t, shippingID1, shippingID2)
 	})
-}
\ No newline at end of file
+
+	t.Run("retries with the same idempotency key", func(t *testing.T) {
+		req := ProcessShippingRequest{OrderID: "order-123", IdempotencyKey: "wf-1/run-1/2"}
+		shippingID1, err1 := activities.ProcessShipping(ctx, req)
+		shippingID2, err2 := activities.ProcessShipping(ctx, req)
+
+		assert.NoError(t, err1)
+		assert.NoError(t, err2)
+		assert.Equal(t, shippingID1, shippingID2)
+	})
+
+	t.Run("shares an explicit key with the reservation", func(t *testing.T) {
+		const key = "order-123-fulfillment"
+		reservationID, err := activities.ReserveInventory(ctx, ReserveInventoryRequest{OrderID: "order-123", IdempotencyKey: key})
+		assert.NoError(t, err)
+		shippingID, err := activities.ProcessShipping(ctx, ProcessShippingRequest{OrderID: "order-123", IdempotencyKey: key})
+		assert.NoError(t, err)
+
+		assert.Contains(t, shippingID, "ship_order-123_")
+		assert.NotEqual(t, reservationID, shippingID)
+	})
+}
@@ -0,0 +1,27 @@
+package activities
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivities_ReleaseInventory(t *testing.T) {
+	activities := NewActivities()
+	ctx := context.Background()
+
+	t.Run("releases a reservation", func(t *testing.T) {
+		reservationID, err := activities.ReserveInventory(ctx, ReserveInventoryRequest{OrderID: "order-123"})
+		assert.NoError(t, err)
+
+		err = activities.ReleaseInventory(ctx, ReleaseInventoryRequest{OrderID: "order-123", ReservationID: reservationID})
+		assert.NoError(t, err)
+	})
+
+	t.Run("releasing twice succeeds", func(t *testing.T) {
+		req := ReleaseInventoryRequest{OrderID: "order-123", ReservationID: "res_order-123_1"}
+		assert.NoError(t, activities.ReleaseInventory(ctx, req))
+		assert.NoError(t, activities.ReleaseInventory(ctx, req))
+	})
+}
@@ -34,4 +34,14 @@ func TestActivities_ReserveInventory(t *testing.T) {
 		assert.NoError(t, err2)
 		assert.NotEqual(t, reservationID1, reservationID2)
 	})
+
+	t.Run("retries with the same idempotency key", func(t *testing.T) {
+		req := ReserveInventoryRequest{OrderID: "order-123", IdempotencyKey: "wf-1/run-1/2"}
+		reservationID1, err1 := activities.ReserveInventory(ctx, req)
+		reservationID2, err2 := activities.ReserveInventory(ctx, req)
+
+		assert.NoError(t, err1)
+		assert.NoError(t, err2)
+		assert.Equal(t, reservationID1, reservationID2)
+	})
 }
\ No newline at end of file
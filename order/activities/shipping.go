@@ -3,26 +3,34 @@ package activities
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type ProcessShippingRequest struct {
-	OrderID string `json:"orderId"`
+	OrderID        string `json:"orderId"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
 }
 
 func (a *Activities) ProcessShipping(ctx context.Context, req ProcessShippingRequest) (string, error) {
-	log.Printf("Processing shipping for order: %s", req.OrderID)
+	logger(ctx).Info("Processing shipping", "OrderID", req.OrderID)
+
+	key := subKey(idempotencyKey(ctx, req.IdempotencyKey), "ship")
+	if shippingID, ok := a.store.lookup(key); ok {
+		logger(ctx).Info("Shipping already processed", "OrderID", req.OrderID, "ShippingID", shippingID)
+		return shippingID, nil
+	}
 	
 	// Simulate shipping processing
 	time.Sleep(300 * time.Millisecond)
 	
-	shippingID := fmt.Sprintf("ship_%s_%s", req.OrderID, uuid.New().String()[:8])
+	shippingID := a.store.issue(key, func() string {
+		return fmt.Sprintf("ship_%s_%s", req.OrderID, uuid.New().String()[:8])
+	})
 	
 	// In a real implementation, you'd integrate with shipping providers
-	log.Printf("Shipping processed with ID: %s", shippingID)
+	logger(ctx).Info("Shipping processed", "OrderID", req.OrderID, "ShippingID", shippingID)
 	
 	return shippingID, nil
-}
\ No newline at end of file
+}
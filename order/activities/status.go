@@ -2,23 +2,30 @@ package activities
 
 import (
 	"context"
-	"log"
 	"time"
 )
 
+// Order statuses recorded by UpdateOrderStatus
+const (
+	OrderStatusProcessing = "processing"
+	OrderStatusCompleted  = "completed"
+	OrderStatusFailed     = "failed"
+	OrderStatusCancelled  = "cancelled"
+)
+
 type UpdateOrderStatusRequest struct {
 	OrderID string `json:"orderId"`
 	Status  string `json:"status"`
 }
 
 func (a *Activities) UpdateOrderStatus(ctx context.Context, req UpdateOrderStatusRequest) error {
-	log.Printf("Updating order %s status to: %s", req.OrderID, req.Status)
+	logger(ctx).Info("Updating order status", "OrderID", req.OrderID, "Status", req.Status)
 	
 	// Simulate database update
 	time.Sleep(50 * time.Millisecond)
 	
 	// In a real implementation, you'd update your order database
-	log.Printf("Order %s status updated to: %s", req.OrderID, req.Status)
+	logger(ctx).Info("Order status updated", "OrderID", req.OrderID, "Status", req.Status)
 	
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,46 @@
+package activities
+
+import "sync"
+
+// fulfillmentStore stands in for the inventory and shipping systems. It
+// remembers the ID issued for each idempotency key so a retried activity gets
+// the original reservation or shipment back instead of creating another.
+type fulfillmentStore struct {
+	mu     sync.Mutex
+	issued map[string]string
+}
+
+func newFulfillmentStore() *fulfillmentStore {
+	return &fulfillmentStore{issued: make(map[string]string)}
+}
+
+// lookup returns the ID already issued for key
+func (s *fulfillmentStore) lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.issued[key]
+	return id, ok
+}
+
+// issue records the ID created for key, returning the existing one when a
+// concurrent attempt issued it first
+func (s *fulfillmentStore) issue(key string, create func() string) string {
+	if key == "" {
+		return create()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.issued[key]; ok {
+		return id
+	}
+	id := create()
+	s.issued[key] = id
+	return id
+}
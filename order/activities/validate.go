@@ -3,7 +3,6 @@ package activities
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 )
 
@@ -12,7 +11,7 @@ type ValidateOrderRequest struct {
 }
 
 func (a *Activities) ValidateOrder(ctx context.Context, req ValidateOrderRequest) (bool, error) {
-	log.Printf("Validating order: %s", req.OrderID)
+	logger(ctx).Info("Validating order", "OrderID", req.OrderID)
 	
 	// Simulate validation logic
 	if req.OrderID == "" {
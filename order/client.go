@@ -11,37 +11,60 @@ import (
 
 // ProcessOrderRequest represents a request to process an order
 type ProcessOrderRequest struct {
-	OrderID string `json:"orderId"`
-	UserID  string `json:"userId,omitempty"` // Optional for search attributes
+	OrderID string `json:"orderId" validate:"required,max=128"`
+	UserID  string `json:"userId,omitempty" validate:"max=128"` // Optional for search attributes
 }
 
 // CancelOrderRequest represents a request to cancel an order
 type CancelOrderRequest struct {
-	OrderID string `json:"orderId"`
-	UserID  string `json:"userId,omitempty"` // Optional for search attributes
+	OrderID string `json:"orderId" validate:"required,max=128"`
+	UserID  string `json:"userId,omitempty" validate:"max=128"` // Optional for search attributes
 }
 
 // Client provides methods to execute order workflows
 type Client interface {
 	ProcessOrder(ctx context.Context, req ProcessOrderRequest) (*common.WorkflowResult, error)
 	CancelOrder(ctx context.Context, req CancelOrderRequest) (*common.WorkflowResult, error)
+	GetProcessOrderProgress(ctx context.Context, workflowID, runID string) (*workflows.ProcessOrderProgress, error)
+	GetProcessOrderState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error)
 }
 
 // orderClient implements the Client interface
 type orderClient struct {
-	commonClient *common.Client
+	commonClient        *common.Client
+	processOrderVersion int
+}
+
+// ClientOption configures the order client
+type ClientOption func(*orderClient)
+
+// WithProcessOrderVersion starts ProcessOrder executions on version instead
+// of DefaultProcessOrderVersion, e.g. to hold back a new version
+func WithProcessOrderVersion(version int) ClientOption {
+	return func(c *orderClient) {
+		c.processOrderVersion = version
+	}
 }
 
 // NewClient creates a new order workflow client
-func NewClient(temporalClient temporalclient.Client, taskQueue string) Client {
-	return &orderClient{
-		commonClient: common.NewClient(temporalClient, taskQueue),
+func NewClient(temporalClient temporalclient.Client, taskQueue string, opts ...ClientOption) Client {
+	c := &orderClient{
+		commonClient:        common.NewClient(temporalClient, taskQueue),
+		processOrderVersion: DefaultProcessOrderVersion,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 
-// ProcessOrder starts a ProcessOrder workflow
+// ProcessOrder starts a ProcessOrder workflow on the client's version
 func (c *orderClient) ProcessOrder(ctx context.Context, req ProcessOrderRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
 	workflowInput := workflows.OrderRequest{OrderID: req.OrderID}
 	
 	// Build search attributes
@@ -51,7 +74,7 @@ func (c *orderClient) ProcessOrder(ctx context.Context, req ProcessOrderRequest)
 	}
 	
 	return c.commonClient.ExecuteWorkflow(ctx, common.WorkflowExecutionParams{
-		WorkflowType:     "ProcessOrder.v1",
+		WorkflowType:     common.WorkflowType("ProcessOrder", c.processOrderVersion),
 		WorkflowIDPrefix: "process-order",
 		WorkflowInput:    workflowInput,
 		SearchAttributes: searchAttributes,
@@ -61,6 +84,10 @@ func (c *orderClient) ProcessOrder(ctx context.Context, req ProcessOrderRequest)
 
 // CancelOrder starts a CancelOrder workflow
 func (c *orderClient) CancelOrder(ctx context.Context, req CancelOrderRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
 	workflowInput := workflows.OrderRequest{OrderID: req.OrderID}
 	
 	// Build search attributes
@@ -70,10 +97,28 @@ func (c *orderClient) CancelOrder(ctx context.Context, req CancelOrderRequest) (
 	}
 	
 	return c.commonClient.ExecuteWorkflow(ctx, common.WorkflowExecutionParams{
-		WorkflowType:     "CancelOrder.v1",
+		WorkflowType:     CancelOrderWorkflow,
 		WorkflowIDPrefix: "cancel-order",
 		WorkflowInput:    workflowInput,
 		SearchAttributes: searchAttributes,
 		SuccessMessage:   fmt.Sprintf("Order cancellation workflow started for order %s", req.OrderID),
 	})
+}
+
+// GetProcessOrderProgress queries the progress of a running or completed ProcessOrder workflow
+func (c *orderClient) GetProcessOrderProgress(ctx context.Context, workflowID, runID string) (*workflows.ProcessOrderProgress, error) {
+	var progress workflows.ProcessOrderProgress
+	if err := c.commonClient.QueryWorkflow(ctx, workflowID, runID, common.QueryProgress, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// GetProcessOrderState queries the compact state of a running or completed ProcessOrder workflow
+func (c *orderClient) GetProcessOrderState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error) {
+	var state common.WorkflowState
+	if err := c.commonClient.QueryWorkflow(ctx, workflowID, runID, common.QueryState, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
 }
\ No newline at end of file
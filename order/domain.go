@@ -0,0 +1,68 @@
+package order
+
+import (
+	"errors"
+
+	"simple-temporal-workflow/api"
+	"simple-temporal-workflow/domain"
+	"simple-temporal-workflow/order/activities"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Scopes required by the order endpoints
+const (
+	ScopeWrite = "order:write"
+)
+
+func init() {
+	domain.Register(NewDomain())
+}
+
+// Domain wires the order workflows, activities and client into the service
+type Domain struct {
+	orchestrator *Orchestrator
+	client       Client
+}
+
+// NewDomain creates the order domain with its default activities
+func NewDomain() *Domain {
+	orderActivities := activities.NewActivities()
+	orderWorkflows := NewWorkflows(orderActivities)
+
+	return &Domain{
+		orchestrator: NewOrchestrator(orderWorkflows, orderActivities),
+	}
+}
+
+// Name returns the domain name
+func (d *Domain) Name() string {
+	return "order"
+}
+
+// Register registers the order workflows and activities with the worker
+func (d *Domain) Register(w worker.Worker) {
+	d.orchestrator.RegisterWithWorker(w)
+}
+
+// NewClient creates the order workflow client
+func (d *Domain) NewClient(temporalClient client.Client, taskQueue string) {
+	d.orchestrator.SetClient(temporalClient, taskQueue)
+	d.client = NewClient(temporalClient, taskQueue)
+}
+
+// Client returns the order workflow client created by NewClient
+func (d *Domain) Client() Client {
+	return d.client
+}
+
+// Routes registers the order workflow-trigger endpoints, failing route
+// registration when NewClient was not called as the handlers are bound to
+// the client
+func (d *Domain) Routes(router *api.Router) {
+	if d.client == nil {
+		router.Fail(errors.New("order domain routes registered before NewClient"))
+		return
+	}
+	router.Handle("/api/workflows/order/process", ScopeWrite, api.WorkflowHandler("ProcessOrder", d.client.ProcessOrder))
+}
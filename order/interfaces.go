@@ -10,7 +10,8 @@ import (
 
 // Workflows defines the order workflow interface
 type Workflows interface {
-	ProcessOrder(ctx workflow.Context, req workflows.OrderRequest) (string, error)
+	ProcessOrderV1(ctx workflow.Context, req workflows.OrderRequest) (string, error)
+	ProcessOrderV2(ctx workflow.Context, req workflows.OrderRequest) (string, error)
 	CancelOrder(ctx workflow.Context, req workflows.OrderRequest) (bool, error)
 }
 
@@ -18,6 +19,7 @@ type Workflows interface {
 type Activities interface {
 	ValidateOrder(ctx context.Context, req activities.ValidateOrderRequest) (bool, error)
 	ReserveInventory(ctx context.Context, req activities.ReserveInventoryRequest) (string, error)
+	ReleaseInventory(ctx context.Context, req activities.ReleaseInventoryRequest) error
 	ProcessShipping(ctx context.Context, req activities.ProcessShippingRequest) (string, error)
 	UpdateOrderStatus(ctx context.Context, req activities.UpdateOrderStatusRequest) error
 }
\ No newline at end of file
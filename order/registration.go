@@ -1,11 +1,25 @@
 package order
 
 import (
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/order/child"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
 )
 
+// Workflow types registered by the order domain, declared with the child
+// stubs. Both ProcessOrder versions stay registered so v1 executions can
+// finish after v2 is deployed.
+const (
+	ProcessOrderV1Workflow = child.ProcessOrderV1Workflow
+	ProcessOrderV2Workflow = child.ProcessOrderV2Workflow
+	CancelOrderWorkflow    = child.CancelOrderWorkflow
+)
+
+// DefaultProcessOrderVersion is the ProcessOrder version new executions start
+const DefaultProcessOrderVersion = 2
+
 type Orchestrator struct {
 	workflows  Workflows
 	activities Activities
@@ -29,16 +43,18 @@ func (o *Orchestrator) SetClient(client client.Client, taskQueue string) {
 
 func (o *Orchestrator) RegisterWithWorker(w worker.Worker) {
 	// Register workflows with versioning
-	w.RegisterWorkflowWithOptions(o.workflows.ProcessOrder, workflow.RegisterOptions{
-		Name: "ProcessOrder.v1",
+	common.RegisterWorkflowVersions(w, "ProcessOrder", map[int]any{
+		1: o.workflows.ProcessOrderV1,
+		2: o.workflows.ProcessOrderV2,
 	})
 	w.RegisterWorkflowWithOptions(o.workflows.CancelOrder, workflow.RegisterOptions{
-		Name: "CancelOrder.v1",
+		Name: CancelOrderWorkflow,
 	})
 
 	// Register activities
 	w.RegisterActivity(o.activities.ValidateOrder)
 	w.RegisterActivity(o.activities.ReserveInventory)
+	w.RegisterActivity(o.activities.ReleaseInventory)
 	w.RegisterActivity(o.activities.ProcessShipping)
 	w.RegisterActivity(o.activities.UpdateOrderStatus)
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package order
+
+import (
+	"testing"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/order/activities"
+	"simple-temporal-workflow/replay"
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// TestReplay replays the recorded histories in testdata against the
+// workflows the order domain registers. A failure means the change would
+// break executions started before it was deployed: add a version or guard
+// it with a patch instead.
+func TestReplay(t *testing.T) {
+	replay.New(NewDomain().Register).Run(t, "testdata")
+}
+
+// ProcessOrder.v2 is registered as a new type because the v1 histories do
+// not replay on it
+func TestReplay_ProcessOrderV1OnV2(t *testing.T) {
+	orderWorkflows := NewWorkflows(activities.NewActivities())
+	replayer := replay.New(func(w worker.Worker) {
+		w.RegisterWorkflowWithOptions(orderWorkflows.ProcessOrderV2, workflow.RegisterOptions{
+			Name: common.WorkflowType("ProcessOrder", 1),
+		})
+	})
+
+	histories, err := replay.Histories("testdata")
+	assert.NoError(t, err)
+	for _, history := range histories {
+		assert.ErrorContains(t, replayer.ReplayFile(history), "nondeterministic")
+	}
+}
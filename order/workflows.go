@@ -10,8 +10,12 @@ type orderWorkflowAdapter struct {
 	workflows *workflows.Workflows
 }
 
-func (a *orderWorkflowAdapter) ProcessOrder(ctx workflow.Context, req workflows.OrderRequest) (string, error) {
-	return a.workflows.ProcessOrder(ctx, req)
+func (a *orderWorkflowAdapter) ProcessOrderV1(ctx workflow.Context, req workflows.OrderRequest) (string, error) {
+	return a.workflows.ProcessOrderV1(ctx, req)
+}
+
+func (a *orderWorkflowAdapter) ProcessOrderV2(ctx workflow.Context, req workflows.OrderRequest) (string, error) {
+	return a.workflows.ProcessOrderV2(ctx, req)
 }
 
 func (a *orderWorkflowAdapter) CancelOrder(ctx workflow.Context, req workflows.OrderRequest) (bool, error) {
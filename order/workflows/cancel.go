@@ -13,7 +13,7 @@ func (w *Workflows) CancelOrder(ctx workflow.Context, req OrderRequest) (bool, e
 	ctx = common.WithActivityOptions(ctx)
 
 	// Update order status to cancelled
-	err := workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: req.OrderID, Status: "cancelled"}).Get(ctx, nil)
+	err := workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: req.OrderID, Status: activities.OrderStatusCancelled}).Get(ctx, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to cancel order: %w", err)
 	}
@@ -22,6 +22,11 @@ func (m *MockActivities) ReserveInventory(ctx context.Context, req activities.Re
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockActivities) ReleaseInventory(ctx context.Context, req activities.ReleaseInventoryRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
 func (m *MockActivities) ProcessShipping(ctx context.Context, req activities.ProcessShippingRequest) (string, error) {
 	args := m.Called(ctx, req)
 	return args.String(0), args.Error(1)
@@ -14,49 +14,101 @@ type OrderRequest struct {
 	OrderID string `json:"orderId"`
 }
 
+// ProcessOrder steps reported through the progress query
+const (
+	StepValidateOrder     = "validate_order"
+	StepReserveInventory  = "reserve_inventory"
+	StepProcessShipping   = "process_shipping"
+	StepUpdateOrderStatus = "update_order_status"
+)
+
+// PatchReleaseInventory releases the inventory reservation when shipping
+// fails in ProcessOrder.v1 executions that reach shipping after the fix
+const PatchReleaseInventory = "release-inventory-on-shipping-failure"
+
+// ProcessOrderProgress represents the progress of a ProcessOrder workflow
+type ProcessOrderProgress struct {
+	common.Progress
+	ReservationID string `json:"reservationId,omitempty"`
+	ShippingID    string `json:"shippingId,omitempty"`
+}
+
 // Activities interface for order activities
 type Activities interface {
 	ValidateOrder(ctx context.Context, req activities.ValidateOrderRequest) (bool, error)
 	ReserveInventory(ctx context.Context, req activities.ReserveInventoryRequest) (string, error)
+	ReleaseInventory(ctx context.Context, req activities.ReleaseInventoryRequest) error
 	ProcessShipping(ctx context.Context, req activities.ProcessShippingRequest) (string, error)
 	UpdateOrderStatus(ctx context.Context, req activities.UpdateOrderStatusRequest) error
 }
 
-func (w *Workflows) ProcessOrder(ctx workflow.Context, req OrderRequest) (string, error) {
+// ProcessOrderV1 validates the order, reserves inventory, ships it and marks
+// it completed. It is kept registered for executions started on v1.
+func (w *Workflows) ProcessOrderV1(ctx workflow.Context, req OrderRequest) (string, error) {
+	// Expose intermediate state through query handlers
+	var progress ProcessOrderProgress
+	err := common.SetProgressQueryHandlers(ctx,
+		func() ProcessOrderProgress { return progress },
+		progress.State,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to register query handlers: %w", err)
+	}
+
 	// Apply default activity options
 	ctx = common.WithActivityOptions(ctx)
 
 	// Step 1: Validate order
+	progress.StartStep(StepValidateOrder)
 	var isValid bool
-	err := workflow.ExecuteActivity(ctx, w.activities.ValidateOrder, activities.ValidateOrderRequest{OrderID: req.OrderID}).Get(ctx, &isValid)
+	err = workflow.ExecuteActivity(ctx, w.activities.ValidateOrder, activities.ValidateOrderRequest{OrderID: req.OrderID}).Get(ctx, &isValid)
 	if err != nil {
-		return "", fmt.Errorf("failed to validate order: %w", err)
+		err = fmt.Errorf("failed to validate order: %w", err)
+		progress.Fail(err)
+		return "", err
 	}
 	if !isValid {
-		return "", fmt.Errorf("order validation failed for order %s", req.OrderID)
+		err = fmt.Errorf("order validation failed for order %s", req.OrderID)
+		progress.Fail(err)
+		return "", err
 	}
+	progress.CompleteStep()
 
 	// Step 2: Reserve inventory
-	var reservationID string
-	err = workflow.ExecuteActivity(ctx, w.activities.ReserveInventory, activities.ReserveInventoryRequest{OrderID: req.OrderID}).Get(ctx, &reservationID)
+	progress.StartStep(StepReserveInventory)
+	err = workflow.ExecuteActivity(ctx, w.activities.ReserveInventory, activities.ReserveInventoryRequest{OrderID: req.OrderID}).Get(ctx, &progress.ReservationID)
 	if err != nil {
-		return "", fmt.Errorf("failed to reserve inventory: %w", err)
+		err = fmt.Errorf("failed to reserve inventory: %w", err)
+		progress.Fail(err)
+		return "", err
 	}
+	progress.CompleteStep()
 
 	// Step 3: Process shipping
-	var shippingID string
-	err = workflow.ExecuteActivity(ctx, w.activities.ProcessShipping, activities.ProcessShippingRequest{OrderID: req.OrderID}).Get(ctx, &shippingID)
+	progress.StartStep(StepProcessShipping)
+	err = workflow.ExecuteActivity(ctx, w.activities.ProcessShipping, activities.ProcessShippingRequest{OrderID: req.OrderID}).Get(ctx, &progress.ShippingID)
 	if err != nil {
-		// Compensate: Release inventory reservation
-		workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: req.OrderID, Status: "failed"}).Get(ctx, nil)
-		return "", fmt.Errorf("failed to process shipping: %w", err)
+		err = fmt.Errorf("failed to process shipping: %w", err)
+		progress.Fail(err)
+		// Compensate: Release inventory reservation. Executions that failed
+		// shipping before the release was added only mark the order failed.
+		if common.Patched(ctx, PatchReleaseInventory) {
+			workflow.ExecuteActivity(ctx, w.activities.ReleaseInventory, activities.ReleaseInventoryRequest{OrderID: req.OrderID, ReservationID: progress.ReservationID}).Get(ctx, nil)
+		}
+		workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: req.OrderID, Status: activities.OrderStatusFailed}).Get(ctx, nil)
+		return "", err
 	}
+	progress.CompleteStep()
 
 	// Step 4: Update order status
-	err = workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: req.OrderID, Status: "completed"}).Get(ctx, nil)
+	progress.StartStep(StepUpdateOrderStatus)
+	err = workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: req.OrderID, Status: activities.OrderStatusCompleted}).Get(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to update order status: %w", err)
+		err = fmt.Errorf("failed to update order status: %w", err)
+		progress.Fail(err)
+		return "", err
 	}
+	progress.CompleteStep()
 
-	return fmt.Sprintf("Order %s processed successfully. Shipping: %s", req.OrderID, shippingID), nil
+	return fmt.Sprintf("Order %s processed successfully. Shipping: %s", req.OrderID, progress.ShippingID), nil
 }
\ No newline at end of file
@@ -1,12 +1,15 @@
 package workflows
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"simple-temporal-workflow/common"
 	"simple-temporal-workflow/order/activities"
 )
 
@@ -36,7 +39,7 @@ func (s *ProcessOrderTestSuite) TestProcessOrder_Success() {
 	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "completed"}).Return(nil)
 	
 	// Execute the workflow
-	env.ExecuteWorkflow(workflows.ProcessOrder, req)
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
 	
 	// Verify workflow completed successfully
 	s.True(env.IsWorkflowCompleted())
@@ -62,7 +65,7 @@ func (s *ProcessOrderTestSuite) TestProcessOrder_ValidationFailure() {
 	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(false, nil)
 	
 	// Execute the workflow
-	env.ExecuteWorkflow(workflows.ProcessOrder, req)
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
 	
 	// Verify workflow completed with error
 	s.True(env.IsWorkflowCompleted())
@@ -84,7 +87,7 @@ func (s *ProcessOrderTestSuite) TestProcessOrder_ValidationError() {
 	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(false, validationError)
 	
 	// Execute the workflow
-	env.ExecuteWorkflow(workflows.ProcessOrder, req)
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
 	
 	// Verify workflow completed with error
 	s.True(env.IsWorkflowCompleted())
@@ -108,7 +111,7 @@ func (s *ProcessOrderTestSuite) TestProcessOrder_InventoryReservationFailure() {
 	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("", inventoryError)
 	
 	// Execute the workflow
-	env.ExecuteWorkflow(workflows.ProcessOrder, req)
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
 	
 	// Verify workflow completed with error
 	s.True(env.IsWorkflowCompleted())
@@ -131,17 +134,51 @@ func (s *ProcessOrderTestSuite) TestProcessOrder_ShippingFailureWithCompensation
 	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(true, nil)
 	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
 	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("", shippingError)
-	// Expect compensation - update status to failed
+	// Expect compensation - release the reservation and update status to failed
+	env.OnActivity(mockActivities.ReleaseInventory, mock.Anything, activities.ReleaseInventoryRequest{OrderID: orderID, ReservationID: "reservation-123"}).Return(nil).Once()
 	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "failed"}).Return(nil)
 	
 	// Execute the workflow
-	env.ExecuteWorkflow(workflows.ProcessOrder, req)
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
 	
 	// Verify workflow completed with error
 	s.True(env.IsWorkflowCompleted())
 	s.Error(env.GetWorkflowError())
 	s.Contains(env.GetWorkflowError().Error(), "failed to process shipping")
 	s.Contains(env.GetWorkflowError().Error(), "shipping provider unavailable")
+	env.AssertExpectations(s.T())
+}
+
+func (s *ProcessOrderTestSuite) TestProcessOrder_ShippingFailureBeforePatch() {
+	env := s.NewTestWorkflowEnvironment()
+	
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+	
+	orderID := "test-order-123"
+	req := OrderRequest{OrderID: orderID}
+	
+	// Executions that failed shipping before the patch only mark the order failed
+	env.OnGetVersion(PatchReleaseInventory, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(true, nil)
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
+	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("", errors.New("shipping provider unavailable"))
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "failed"}).Return(nil)
+	released := false
+	env.OnActivity(mockActivities.ReleaseInventory, mock.Anything, mock.Anything).Return(func(ctx context.Context, req activities.ReleaseInventoryRequest) error {
+		released = true
+		return nil
+	}).Maybe()
+	
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
+	
+	// Verify workflow completed with error and left the reservation alone
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to process shipping")
+	s.False(released)
+	env.AssertExpectations(s.T())
 }
 
 func (s *ProcessOrderTestSuite) TestProcessOrder_StatusUpdateFailure() {
@@ -161,11 +198,89 @@ func (s *ProcessOrderTestSuite) TestProcessOrder_StatusUpdateFailure() {
 	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "completed"}).Return(statusError)
 	
 	// Execute the workflow
-	env.ExecuteWorkflow(workflows.ProcessOrder, req)
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
 	
 	// Verify workflow completed with error
 	s.True(env.IsWorkflowCompleted())
 	s.Error(env.GetWorkflowError())
 	s.Contains(env.GetWorkflowError().Error(), "failed to update order status")
 	s.Contains(env.GetWorkflowError().Error(), "database update failed")
+}
+
+func (s *ProcessOrderTestSuite) TestProcessOrder_ProgressQuery() {
+	env := s.NewTestWorkflowEnvironment()
+	
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+	
+	orderID := "test-order-123"
+	req := OrderRequest{OrderID: orderID}
+	
+	// Register mock activities with the test environment
+	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(true, nil)
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
+	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("shipping-456", nil)
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "completed"}).Return(nil)
+	
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	
+	// Verify the progress query
+	value, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress ProcessOrderProgress
+	s.NoError(value.Get(&progress))
+	s.Equal([]string{StepValidateOrder, StepReserveInventory, StepProcessShipping, StepUpdateOrderStatus}, progress.CompletedSteps)
+	s.Equal("reservation-123", progress.ReservationID)
+	s.Equal("shipping-456", progress.ShippingID)
+	s.Empty(progress.LastError)
+	
+	// Verify the state query
+	value, err = env.QueryWorkflow(common.QueryState)
+	s.NoError(err)
+	var state common.WorkflowState
+	s.NoError(value.Get(&state))
+	s.Equal(common.StateCompleted, state.Status)
+}
+
+func (s *ProcessOrderTestSuite) TestProcessOrder_ProgressQueryAfterFailure() {
+	env := s.NewTestWorkflowEnvironment()
+	
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+	
+	orderID := "test-order-123"
+	req := OrderRequest{OrderID: orderID}
+	shippingError := errors.New("shipping provider unavailable")
+	
+	// Register mock activities with the test environment
+	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(true, nil)
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
+	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("", shippingError)
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "failed"}).Return(nil)
+	
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.ProcessOrderV1, req)
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	
+	// Verify the progress query
+	value, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress ProcessOrderProgress
+	s.NoError(value.Get(&progress))
+	s.Equal(StepProcessShipping, progress.CurrentStep)
+	s.Equal([]string{StepValidateOrder, StepReserveInventory}, progress.CompletedSteps)
+	s.Equal("reservation-123", progress.ReservationID)
+	s.Contains(progress.LastError, "shipping provider unavailable")
+	
+	// Verify the state query
+	value, err = env.QueryWorkflow(common.QueryState)
+	s.NoError(err)
+	var state common.WorkflowState
+	s.NoError(value.Get(&state))
+	s.Equal(common.StateFailed, state.Status)
+	s.Equal(StepProcessShipping, state.CurrentStep)
 }
\ No newline at end of file
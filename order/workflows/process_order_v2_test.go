@@ -0,0 +1,124 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/order/activities"
+)
+
+// ProcessOrderV2TestSuite defines the test suite for ProcessOrder.v2 workflow
+type ProcessOrderV2TestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func TestProcessOrderV2TestSuite(t *testing.T) {
+	suite.Run(t, new(ProcessOrderV2TestSuite))
+}
+
+// newProcessingEnv returns an environment whose order is validated and marked processing
+func (s *ProcessOrderV2TestSuite) newProcessingEnv(orderID string) (*testsuite.TestWorkflowEnvironment, *MockActivities) {
+	env := s.NewTestWorkflowEnvironment()
+	mockActivities := &MockActivities{}
+
+	env.OnActivity(mockActivities.ValidateOrder, mock.Anything, activities.ValidateOrderRequest{OrderID: orderID}).Return(true, nil)
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "processing"}).Return(nil).Once()
+
+	return env, mockActivities
+}
+
+// queryProgress returns the order progress
+func (s *ProcessOrderV2TestSuite) queryProgress(env *testsuite.TestWorkflowEnvironment) ProcessOrderProgress {
+	value, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress ProcessOrderProgress
+	s.NoError(value.Get(&progress))
+	return progress
+}
+
+func (s *ProcessOrderV2TestSuite) TestProcessOrderV2_Success() {
+	orderID := "test-order-123"
+	env, mockActivities := s.newProcessingEnv(orderID)
+
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
+	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("shipping-456", nil)
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "completed"}).Return(nil).Once()
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).ProcessOrderV2, OrderRequest{OrderID: orderID})
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Equal("Order test-order-123 processed successfully. Shipping: shipping-456", result)
+
+	progress := s.queryProgress(env)
+	s.Equal([]string{StepValidateOrder, StepMarkProcessing, StepReserveInventory, StepProcessShipping, StepUpdateOrderStatus}, progress.CompletedSteps)
+	env.AssertExpectations(s.T())
+}
+
+func (s *ProcessOrderV2TestSuite) TestProcessOrderV2_ShippingFailureReleasesInventory() {
+	orderID := "test-order-123"
+	env, mockActivities := s.newProcessingEnv(orderID)
+
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
+	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("", errors.New("shipping provider unavailable"))
+	env.OnActivity(mockActivities.ReleaseInventory, mock.Anything, activities.ReleaseInventoryRequest{OrderID: orderID, ReservationID: "reservation-123"}).Return(nil).Once()
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "failed"}).Return(nil).Once()
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).ProcessOrderV2, OrderRequest{OrderID: orderID})
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to process shipping")
+
+	progress := s.queryProgress(env)
+	s.Equal([]string{StepValidateOrder, StepMarkProcessing, StepReserveInventory, StepReleaseInventory}, progress.CompletedSteps)
+	env.AssertExpectations(s.T())
+}
+
+func (s *ProcessOrderV2TestSuite) TestProcessOrderV2_ReservationFailure() {
+	orderID := "test-order-123"
+	env, mockActivities := s.newProcessingEnv(orderID)
+
+	// Nothing was reserved, so there is nothing to release
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("", errors.New("insufficient inventory"))
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "failed"}).Return(nil).Once()
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).ProcessOrderV2, OrderRequest{OrderID: orderID})
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to reserve inventory")
+	s.Contains(env.GetWorkflowError().Error(), "insufficient inventory")
+	env.AssertExpectations(s.T())
+}
+
+func (s *ProcessOrderV2TestSuite) TestProcessOrderV2_ReleaseFailure() {
+	orderID := "test-order-123"
+	env, mockActivities := s.newProcessingEnv(orderID)
+
+	env.OnActivity(mockActivities.ReserveInventory, mock.Anything, activities.ReserveInventoryRequest{OrderID: orderID}).Return("reservation-123", nil)
+	env.OnActivity(mockActivities.ProcessShipping, mock.Anything, activities.ProcessShippingRequest{OrderID: orderID}).Return("", errors.New("shipping provider unavailable"))
+	env.OnActivity(mockActivities.ReleaseInventory, mock.Anything, mock.Anything).Return(errors.New("inventory service down"))
+	env.OnActivity(mockActivities.UpdateOrderStatus, mock.Anything, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: "failed"}).Return(nil).Once()
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).ProcessOrderV2, OrderRequest{OrderID: orderID})
+
+	// The shipping failure is reported, the failed release is recorded
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to process shipping")
+	s.NotContains(env.GetWorkflowError().Error(), "inventory service down")
+
+	progress := s.queryProgress(env)
+	s.Equal(StepReleaseInventory, progress.CurrentStep)
+	s.Contains(progress.LastError, "failed to release inventory")
+	env.AssertExpectations(s.T())
+}
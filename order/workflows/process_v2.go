@@ -0,0 +1,114 @@
+package workflows
+
+import (
+	"fmt"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/order/activities"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Steps added by ProcessOrder.v2
+const (
+	StepMarkProcessing   = "mark_processing"
+	StepReleaseInventory = "release_inventory"
+)
+
+// ProcessOrderV2 marks the order processing as soon as it is validated, and
+// on a failed reservation or shipment releases what was reserved and marks
+// the order failed.
+func (w *Workflows) ProcessOrderV2(ctx workflow.Context, req OrderRequest) (string, error) {
+	// Expose intermediate state through query handlers
+	var progress ProcessOrderProgress
+	err := common.SetProgressQueryHandlers(ctx,
+		func() ProcessOrderProgress { return progress },
+		progress.State,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to register query handlers: %w", err)
+	}
+
+	// Apply default activity options
+	ctx = common.WithActivityOptions(ctx)
+
+	// Step 1: Validate order
+	progress.StartStep(StepValidateOrder)
+	var isValid bool
+	err = workflow.ExecuteActivity(ctx, w.activities.ValidateOrder, activities.ValidateOrderRequest{OrderID: req.OrderID}).Get(ctx, &isValid)
+	if err != nil {
+		err = fmt.Errorf("failed to validate order: %w", err)
+		progress.Fail(err)
+		return "", err
+	}
+	if !isValid {
+		err = fmt.Errorf("order validation failed for order %s", req.OrderID)
+		progress.Fail(err)
+		return "", err
+	}
+	progress.CompleteStep()
+
+	// Step 2: Mark order processing
+	progress.StartStep(StepMarkProcessing)
+	if err := w.updateOrderStatus(ctx, req.OrderID, activities.OrderStatusProcessing); err != nil {
+		err = fmt.Errorf("failed to update order status: %w", err)
+		progress.Fail(err)
+		return "", err
+	}
+	progress.CompleteStep()
+
+	// Step 3: Reserve inventory
+	progress.StartStep(StepReserveInventory)
+	err = workflow.ExecuteActivity(ctx, w.activities.ReserveInventory, activities.ReserveInventoryRequest{OrderID: req.OrderID}).Get(ctx, &progress.ReservationID)
+	if err != nil {
+		err = fmt.Errorf("failed to reserve inventory: %w", err)
+		progress.Fail(err)
+		return "", w.failOrder(ctx, req.OrderID, &progress, err)
+	}
+	progress.CompleteStep()
+
+	// Step 4: Process shipping
+	progress.StartStep(StepProcessShipping)
+	err = workflow.ExecuteActivity(ctx, w.activities.ProcessShipping, activities.ProcessShippingRequest{OrderID: req.OrderID}).Get(ctx, &progress.ShippingID)
+	if err != nil {
+		err = fmt.Errorf("failed to process shipping: %w", err)
+		progress.Fail(err)
+		return "", w.failOrder(ctx, req.OrderID, &progress, err)
+	}
+	progress.CompleteStep()
+
+	// Step 5: Update order status
+	progress.StartStep(StepUpdateOrderStatus)
+	if err := w.updateOrderStatus(ctx, req.OrderID, activities.OrderStatusCompleted); err != nil {
+		err = fmt.Errorf("failed to update order status: %w", err)
+		progress.Fail(err)
+		return "", err
+	}
+	progress.CompleteStep()
+
+	return fmt.Sprintf("Order %s processed successfully. Shipping: %s", req.OrderID, progress.ShippingID), nil
+}
+
+// failOrder releases the inventory reservation, if one was made, and marks
+// the order failed. Compensation is best effort: cause is returned either
+// way, and a release that did not go through is recorded in the progress.
+func (w *Workflows) failOrder(ctx workflow.Context, orderID string, progress *ProcessOrderProgress, cause error) error {
+	logger(ctx).Warn("Failing order", "OrderID", orderID, "Error", cause)
+	if progress.ReservationID != "" {
+		progress.StartStep(StepReleaseInventory)
+		err := workflow.ExecuteActivity(ctx, w.activities.ReleaseInventory, activities.ReleaseInventoryRequest{OrderID: orderID, ReservationID: progress.ReservationID}).Get(ctx, nil)
+		if err != nil {
+			logger(ctx).Error("Failed to release inventory", "OrderID", orderID, "ReservationID", progress.ReservationID, "Error", err)
+			progress.Fail(fmt.Errorf("%w; failed to release inventory: %v", cause, err))
+		} else {
+			progress.CompleteStep()
+		}
+	}
+
+	w.updateOrderStatus(ctx, orderID, activities.OrderStatusFailed)
+	return cause
+}
+
+// updateOrderStatus records the order status
+func (w *Workflows) updateOrderStatus(ctx workflow.Context, orderID, status string) error {
+	return workflow.ExecuteActivity(ctx, w.activities.UpdateOrderStatus, activities.UpdateOrderStatusRequest{OrderID: orderID, Status: status}).Get(ctx, nil)
+}
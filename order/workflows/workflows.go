@@ -1,5 +1,11 @@
 package workflows
 
+import (
+	"simple-temporal-workflow/logging"
+	"go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+)
+
 // Workflows provides order workflow implementations
 type Workflows struct {
 	activities Activities
@@ -10,4 +16,12 @@ func NewWorkflows(activities Activities) *Workflows {
 	return &Workflows{
 		activities: activities,
 	}
-}
\ No newline at end of file
+}
+
+// domainName tags the workflows' logs
+const domainName = "order"
+
+// logger returns the workflow's replay-safe logger, tagged with the domain
+func logger(ctx workflow.Context) log.Logger {
+	return logging.Workflow(ctx, domainName)
+}
@@ -1,12 +1,50 @@
 package activities
 
+import (
+	"context"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/logging"
+	"simple-temporal-workflow/payment/gateway"
+	"go.temporal.io/sdk/log"
+)
+
 // Activities provides payment activity implementations
 type Activities struct {
-	// In a real implementation, these would be your payment gateway clients,
-	// database connections, etc.
+	// In a real implementation, these would also include database
+	// connections, etc.
+	gateway gateway.PaymentGateway
+}
+
+// NewActivities creates a new payment activities service charging through gw
+func NewActivities(gw gateway.PaymentGateway) *Activities {
+	return &Activities{
+		gateway: gw,
+	}
+}
+
+// domainName tags the activities' logs
+const domainName = "payment"
+
+// logger returns the running activity's logger, tagged with the domain
+func logger(ctx context.Context) log.Logger {
+	return logging.Activity(ctx, domainName)
 }
 
-// NewActivities creates a new payment activities service
-func NewActivities() *Activities {
-	return &Activities{}
-}
\ No newline at end of file
+// idempotencyKey returns the key set on a request, falling back to one
+// derived from the running activity so retries reuse it
+func idempotencyKey(ctx context.Context, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return common.ActivityIdempotencyKey(ctx)
+}
+
+// subKey scopes an idempotency key to one of several gateway calls made by
+// the same activity
+func subKey(key, call string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "/" + call
+}
@@ -0,0 +1,68 @@
+package activities
+
+import (
+	"context"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
+)
+
+// AuthorizePaymentRequest represents the input for payment authorization
+type AuthorizePaymentRequest struct {
+	PaymentID      string       `json:"paymentId"`
+	Amount         common.Money `json:"amount"`
+	IdempotencyKey string       `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
+}
+
+// CapturePaymentRequest represents the input for capturing an authorization
+type CapturePaymentRequest struct {
+	PaymentID       string       `json:"paymentId"`
+	AuthorizationID string       `json:"authorizationId"`
+	Amount          common.Money `json:"amount"`                   // Zero captures the authorized amount
+	IdempotencyKey  string       `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
+}
+
+// VoidPaymentRequest represents the input for voiding an authorization
+type VoidPaymentRequest struct {
+	PaymentID       string `json:"paymentId"`
+	AuthorizationID string `json:"authorizationId"`
+	IdempotencyKey  string `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
+}
+
+// AuthorizePayment reserves the payment amount, returning the authorization ID
+func (a *Activities) AuthorizePayment(ctx context.Context, req AuthorizePaymentRequest) (string, error) {
+	logger(ctx).Info("Authorizing payment", "PaymentID", req.PaymentID, "Amount", req.Amount.String())
+
+	auth, err := a.gateway.Authorize(ctx, gateway.AuthorizeRequest{PaymentID: req.PaymentID, Amount: req.Amount, IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey)})
+	if err != nil {
+		return "", gatewayError("authorization", err)
+	}
+
+	logger(ctx).Info("Payment authorized", "PaymentID", req.PaymentID, "AuthorizationID", auth.ID)
+	return auth.ID, nil
+}
+
+// CapturePayment settles an authorization, returning the capture transaction ID
+func (a *Activities) CapturePayment(ctx context.Context, req CapturePaymentRequest) (string, error) {
+	logger(ctx).Info("Capturing payment", "PaymentID", req.PaymentID, "AuthorizationID", req.AuthorizationID)
+
+	capture, err := a.gateway.Capture(ctx, gateway.CaptureRequest{AuthorizationID: req.AuthorizationID, Amount: req.Amount, IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey)})
+	if err != nil {
+		return "", gatewayError("capture", err)
+	}
+
+	logger(ctx).Info("Payment captured", "PaymentID", req.PaymentID, "TransactionID", capture.ID)
+	return capture.ID, nil
+}
+
+// VoidPayment releases an uncaptured authorization
+func (a *Activities) VoidPayment(ctx context.Context, req VoidPaymentRequest) error {
+	logger(ctx).Info("Voiding authorization", "PaymentID", req.PaymentID, "AuthorizationID", req.AuthorizationID)
+
+	if _, err := a.gateway.Void(ctx, gateway.VoidRequest{AuthorizationID: req.AuthorizationID, IdempotencyKey: idempotencyKey(ctx, req.IdempotencyKey)}); err != nil {
+		return gatewayError("void", err)
+	}
+
+	logger(ctx).Info("Authorization voided", "PaymentID", req.PaymentID, "AuthorizationID", req.AuthorizationID)
+	return nil
+}
@@ -0,0 +1,52 @@
+package activities
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
+)
+
+func TestActivities_AuthorizeCaptureVoid(t *testing.T) {
+	ctx := context.Background()
+	amount := common.NewMoney(9999, "USD")
+
+	t.Run("authorize then capture", func(t *testing.T) {
+		activities := NewActivities(gateway.NewFake())
+
+		authID, err := activities.AuthorizePayment(ctx, AuthorizePaymentRequest{PaymentID: "payment-123", Amount: amount})
+		require.NoError(t, err)
+		assert.Contains(t, authID, "auth_payment-123_")
+
+		transactionID, err := activities.CapturePayment(ctx, CapturePaymentRequest{PaymentID: "payment-123", AuthorizationID: authID, Amount: common.NewMoney(5000, "USD")})
+		require.NoError(t, err)
+		assert.Contains(t, transactionID, "txn_payment-123_")
+
+		// Only the captured amount can be refunded
+		_, err = activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(5001, "USD")})
+		assertErrorType(t, err, ErrTypeOverRefund)
+	})
+
+	t.Run("authorize then void", func(t *testing.T) {
+		activities := NewActivities(gateway.NewFake())
+
+		authID, err := activities.AuthorizePayment(ctx, AuthorizePaymentRequest{PaymentID: "payment-123", Amount: amount})
+		require.NoError(t, err)
+
+		require.NoError(t, activities.VoidPayment(ctx, VoidPaymentRequest{PaymentID: "payment-123", AuthorizationID: authID}))
+
+		_, err = activities.CapturePayment(ctx, CapturePaymentRequest{PaymentID: "payment-123", AuthorizationID: authID})
+		assertErrorType(t, err, ErrTypeInvalidTransaction)
+	})
+
+	t.Run("declined authorization", func(t *testing.T) {
+		fake := gateway.NewFake()
+		fake.Script(gateway.OpAuthorize, gateway.Decline("do_not_honor"))
+
+		_, err := NewActivities(fake).AuthorizePayment(ctx, AuthorizePaymentRequest{PaymentID: "payment-123", Amount: amount})
+		assertErrorType(t, err, ErrTypePaymentDeclined)
+	})
+}
@@ -2,29 +2,48 @@ package activities
 
 import (
 	"context"
-	"fmt"
-	"log"
-	"time"
+	"errors"
 
-	"github.com/google/uuid"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
 )
 
 // ChargePaymentRequest represents the input for payment charging
 type ChargePaymentRequest struct {
-	PaymentID string  `json:"paymentId"`
-	Amount    float64 `json:"amount"`
+	PaymentID      string       `json:"paymentId"`
+	Amount         common.Money `json:"amount"`
+	IdempotencyKey string       `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
 }
 
+// ChargePayment authorizes and immediately captures the payment amount,
+// returning the capture transaction ID. Retries reuse the idempotency key so
+// the gateway never charges twice.
 func (a *Activities) ChargePayment(ctx context.Context, req ChargePaymentRequest) (string, error) {
-	log.Printf("Charging payment: %s for amount: %.2f", req.PaymentID, req.Amount)
-	
-	// Simulate payment processing
-	time.Sleep(500 * time.Millisecond)
-	
-	transactionID := fmt.Sprintf("txn_%s_%s", req.PaymentID, uuid.New().String()[:8])
-	
-	// In a real implementation, you'd integrate with payment gateways
-	log.Printf("Payment charged successfully. Transaction ID: %s", transactionID)
-	
-	return transactionID, nil
-}
\ No newline at end of file
+	logger(ctx).Info("Charging payment", "PaymentID", req.PaymentID, "Amount", req.Amount.String())
+	key := idempotencyKey(ctx, req.IdempotencyKey)
+
+	auth, err := a.gateway.Authorize(ctx, gateway.AuthorizeRequest{PaymentID: req.PaymentID, Amount: req.Amount, IdempotencyKey: subKey(key, "authorize")})
+	if err != nil {
+		return "", gatewayError("authorization", err)
+	}
+
+	capture, err := a.gateway.Capture(ctx, gateway.CaptureRequest{AuthorizationID: auth.ID, Amount: req.Amount, IdempotencyKey: subKey(key, "capture")})
+	if errors.Is(err, gateway.ErrTimeout) {
+		// The capture may have gone through; the retry resolves it by key
+		return "", gatewayError("capture", err)
+	}
+	if err != nil {
+		// Release the held funds rather than leaving the authorization dangling
+		if _, voidErr := a.gateway.Void(ctx, gateway.VoidRequest{AuthorizationID: auth.ID, IdempotencyKey: subKey(key, "void")}); voidErr != nil {
+			logger(ctx).Error("Failed to void authorization", "PaymentID", req.PaymentID, "AuthorizationID", auth.ID, "Error", voidErr)
+		}
+		return "", gatewayError("capture", err)
+	}
+	if capture.Duplicate {
+		logger(ctx).Info("Gateway returned existing capture", "PaymentID", req.PaymentID, "TransactionID", capture.ID)
+	}
+
+	logger(ctx).Info("Payment charged", "PaymentID", req.PaymentID, "TransactionID", capture.ID)
+
+	return capture.ID, nil
+}
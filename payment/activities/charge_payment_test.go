@@ -2,36 +2,43 @@ package activities
 
 import (
 	"context"
+	"errors"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
 )
 
 func TestActivities_ChargePayment(t *testing.T) {
-	activities := NewActivities()
+	fake := gateway.NewFake()
+	activities := NewActivities(fake)
 	ctx := context.Background()
 
 	t.Run("successful charge", func(t *testing.T) {
-		start := time.Now()
-		transactionID, err := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: "payment-123", Amount: 99.99})
-		elapsed := time.Since(start)
+		transactionID, err := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")})
 		
 		assert.NoError(t, err)
 		assert.NotEmpty(t, transactionID)
 		assert.Contains(t, transactionID, "txn_payment-123_")
-		assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+
+		capture, err := fake.GetTransaction(ctx, transactionID)
+		require.NoError(t, err)
+		assert.Equal(t, gateway.TransactionCapture, capture.Type)
+		assert.Equal(t, common.NewMoney(9999, "USD"), capture.Amount)
 	})
 
 	t.Run("different amounts", func(t *testing.T) {
 		testCases := []struct {
 			name      string
 			paymentID string
-			amount    float64
+			amount    common.Money
 		}{
-			{"small amount", "payment-1", 1.00},
-			{"large amount", "payment-2", 9999.99},
-			{"decimal amount", "payment-3", 123.45},
+			{"small amount", "payment-1", common.NewMoney(100, "USD")},
+			{"large amount", "payment-2", common.NewMoney(999999, "USD")},
+			{"decimal amount", "payment-3", common.NewMoney(12345, "USD")},
 		}
 
 		for _, tc := range testCases {
@@ -46,11 +53,99 @@ func TestActivities_ChargePayment(t *testing.T) {
 	})
 
 	t.Run("generates unique transaction IDs", func(t *testing.T) {
-		txnID1, err1 := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: "payment-123", Amount: 99.99})
-		txnID2, err2 := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: "payment-123", Amount: 99.99})
+		txnID1, err1 := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")})
+		txnID2, err2 := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")})
 		
 		assert.NoError(t, err1)
 		assert.NoError(t, err2)
 		assert.NotEqual(t, txnID1, txnID2)
 	})
+}
+
+func TestActivities_ChargePaymentGatewayFailures(t *testing.T) {
+	ctx := context.Background()
+	req := ChargePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}
+
+	t.Run("declined authorization is not retried", func(t *testing.T) {
+		fake := gateway.NewFake()
+		fake.Script(gateway.OpAuthorize, gateway.Decline("insufficient_funds"))
+
+		_, err := NewActivities(fake).ChargePayment(ctx, req)
+
+		var appErr *temporal.ApplicationError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, ErrTypePaymentDeclined, appErr.Type())
+		assert.True(t, appErr.NonRetryable())
+		assert.Contains(t, err.Error(), "insufficient_funds")
+		assert.Equal(t, 0, fake.Calls(gateway.OpCapture))
+	})
+
+	t.Run("declined capture voids the authorization", func(t *testing.T) {
+		fake := gateway.NewFake()
+		fake.Script(gateway.OpCapture, gateway.Decline("expired_card"))
+
+		_, err := NewActivities(fake).ChargePayment(ctx, req)
+
+		assert.ErrorIs(t, err, gateway.ErrDeclined)
+		assert.Equal(t, 1, fake.Calls(gateway.OpVoid))
+	})
+
+	t.Run("timed out capture is retried against the same authorization", func(t *testing.T) {
+		fake := gateway.NewFake()
+		activities := NewActivities(fake)
+		fake.Script(gateway.OpCapture, gateway.Timeout())
+		keyed := req
+		keyed.IdempotencyKey = "wf-1/run-1/5"
+
+		_, err := activities.ChargePayment(ctx, keyed)
+		assert.ErrorIs(t, err, gateway.ErrTimeout)
+		var appErr *temporal.ApplicationError
+		assert.False(t, errors.As(err, &appErr))
+		assert.Equal(t, 0, fake.Calls(gateway.OpVoid))
+
+		transactionID, err := activities.ChargePayment(ctx, keyed)
+		require.NoError(t, err)
+		capture, err := fake.GetTransaction(ctx, transactionID)
+		require.NoError(t, err)
+		auth, err := fake.GetTransaction(ctx, capture.ParentID)
+		require.NoError(t, err)
+		assert.False(t, auth.Duplicate)
+		assert.Equal(t, 2, fake.Calls(gateway.OpAuthorize))
+	})
+
+	t.Run("retry after a lost response does not charge twice", func(t *testing.T) {
+		fake := gateway.NewFake()
+		activities := NewActivities(fake)
+		fake.Script(gateway.OpCapture, gateway.TimeoutAfterProcessing())
+		keyed := req
+		keyed.IdempotencyKey = "wf-1/run-1/5"
+
+		_, err := activities.ChargePayment(ctx, keyed)
+		assert.ErrorIs(t, err, gateway.ErrTimeout)
+
+		first, err := activities.ChargePayment(ctx, keyed)
+		require.NoError(t, err)
+		second, err := activities.ChargePayment(ctx, keyed)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		// A different key is a different charge
+		keyed.IdempotencyKey = "wf-2/run-1/5"
+		third, err := activities.ChargePayment(ctx, keyed)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, third)
+	})
+
+	t.Run("duplicate capture returns the original transaction", func(t *testing.T) {
+		fake := gateway.NewFake()
+		activities := NewActivities(fake)
+		first, err := activities.ChargePayment(ctx, req)
+		require.NoError(t, err)
+
+		fake.Script(gateway.OpCapture, gateway.Duplicate())
+		second, err := activities.ChargePayment(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
 }
\ No newline at end of file
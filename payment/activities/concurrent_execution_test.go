@@ -6,10 +6,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"simple-temporal-workflow/payment/gateway"
+	"simple-temporal-workflow/common"
 )
 
 func TestActivities_ConcurrentExecution(t *testing.T) {
-	activities := NewActivities()
+	activities := NewActivities(gateway.NewFake())
 	ctx := context.Background()
 
 	t.Run("concurrent payment processing", func(t *testing.T) {
@@ -21,7 +23,7 @@ func TestActivities_ConcurrentExecution(t *testing.T) {
 		for i := 0; i < numPayments; i++ {
 			go func(id int) {
 				paymentID := fmt.Sprintf("payment-%d", id)
-				transactionID, err := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: paymentID, Amount: 100.0})
+				transactionID, err := activities.ChargePayment(ctx, ChargePaymentRequest{PaymentID: paymentID, Amount: common.NewMoney(10000, "USD")})
 				if err != nil {
 					errors <- err
 					return
@@ -0,0 +1,31 @@
+package activities
+
+import (
+	"errors"
+	"fmt"
+
+	"simple-temporal-workflow/payment/gateway"
+	"go.temporal.io/sdk/temporal"
+)
+
+// Error types of payment failures that retrying cannot fix
+const (
+	ErrTypePaymentDeclined    = "PaymentDeclined"
+	ErrTypeInvalidTransaction = "InvalidTransaction"
+	ErrTypePaymentNotCharged  = "PaymentNotCharged"
+	ErrTypeOverRefund         = "OverRefund"
+	ErrTypeCurrencyMismatch   = "CurrencyMismatch"
+)
+
+// gatewayError marks declines and invalid transactions as non-retryable.
+// Timeouts and other failures are returned as is so the activity retries.
+func gatewayError(operation string, err error) error {
+	switch {
+	case errors.Is(err, gateway.ErrDeclined):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("%s declined: %v", operation, err), ErrTypePaymentDeclined, err)
+	case errors.Is(err, gateway.ErrInvalidTransaction), errors.Is(err, gateway.ErrTransactionNotFound):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("%s rejected: %v", operation, err), ErrTypeInvalidTransaction, err)
+	default:
+		return fmt.Errorf("%s failed: %w", operation, err)
+	}
+}
@@ -0,0 +1,80 @@
+package activities
+
+import (
+	"context"
+	"fmt"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
+	"go.temporal.io/sdk/temporal"
+)
+
+// refundBalance is a payment's capture and the refunds made against it, as
+// recorded by the gateway. The gateway is the payment state every worker
+// shares, so any worker can check a refund against the original charge.
+type refundBalance struct {
+	capture gateway.Transaction
+	refunds []gateway.Transaction // In the order the gateway made them
+}
+
+// loadRefundBalance reads the latest capture of a payment and its refunds
+func (a *Activities) loadRefundBalance(ctx context.Context, paymentID string) (refundBalance, error) {
+	transactions, err := a.gateway.ListTransactions(ctx, paymentID)
+	if err != nil {
+		return refundBalance{}, gatewayError("transaction lookup", err)
+	}
+
+	var balance refundBalance
+	for _, txn := range transactions {
+		if txn.Type == gateway.TransactionCapture {
+			balance.capture = txn
+		}
+	}
+	if balance.capture.ID == "" {
+		return refundBalance{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("payment %s has not been charged", paymentID), ErrTypePaymentNotCharged, nil)
+	}
+
+	for _, txn := range transactions {
+		if txn.Type == gateway.TransactionRefund && txn.ParentID == balance.capture.ID {
+			balance.refunds = append(balance.refunds, txn)
+		}
+	}
+	return balance, nil
+}
+
+// remaining returns the part of the capture not refunded yet
+func (b refundBalance) remaining() (common.Money, error) {
+	remaining := b.capture.Amount
+	for _, refund := range b.refunds {
+		var err error
+		if remaining, err = remaining.Sub(refund.Amount); err != nil {
+			return common.Money{}, err
+		}
+	}
+	return remaining, nil
+}
+
+// find returns the refund made under a reference
+func (b refundBalance) find(reference string) (gateway.Transaction, bool) {
+	for _, refund := range b.refunds {
+		if refund.Reference == reference {
+			return refund, true
+		}
+	}
+	return gateway.Transaction{}, false
+}
+
+// record describes a refund with the payment's refund totals as of that
+// refund, so repeating it returns the original record
+func (b refundBalance) record(refundID string) (RefundRecord, bool) {
+	refunded := common.NewMoney(0, b.capture.Amount.Currency)
+	for _, refund := range b.refunds {
+		refunded, _ = refunded.Add(refund.Amount)
+		if refund.ID == refundID {
+			remaining, _ := b.capture.Amount.Sub(refunded)
+			return RefundRecord{RefundID: refund.ID, Amount: refund.Amount, Refunded: refunded, Remaining: remaining}, true
+		}
+	}
+	return RefundRecord{}, false
+}
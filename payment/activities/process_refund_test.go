@@ -2,47 +2,191 @@ package activities
 
 import (
 	"context"
+	"sync"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
 )
 
+// chargedActivities returns activities with paymentID charged for amount
+func chargedActivities(t *testing.T, paymentID string, amount common.Money) *Activities {
+	return chargedActivitiesWithGateway(t, gateway.NewFake(), paymentID, amount)
+}
+
+func chargedActivitiesWithGateway(t *testing.T, gw gateway.PaymentGateway, paymentID string, amount common.Money) *Activities {
+	activities := NewActivities(gw)
+	_, err := activities.ChargePayment(context.Background(), ChargePaymentRequest{PaymentID: paymentID, Amount: amount})
+	require.NoError(t, err)
+	return activities
+}
+
+func assertErrorType(t *testing.T, err error, errType string) {
+	var appErr *temporal.ApplicationError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, errType, appErr.Type())
+	assert.True(t, appErr.NonRetryable())
+}
+
 func TestActivities_ProcessRefund(t *testing.T) {
-	activities := NewActivities()
 	ctx := context.Background()
 
-	t.Run("successful refund", func(t *testing.T) {
-		start := time.Now()
-		refundID, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
-		elapsed := time.Since(start)
-		
+	t.Run("full refund", func(t *testing.T) {
+		activities := chargedActivities(t, "payment-123", common.NewMoney(9999, "USD"))
+
+		refund, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
+
 		assert.NoError(t, err)
-		assert.NotEmpty(t, refundID)
-		assert.Contains(t, refundID, "ref_payment-123_")
-		assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
-	})
-
-	t.Run("different payment IDs", func(t *testing.T) {
-		paymentIDs := []string{"payment-1", "payment-abc", "payment-xyz-123"}
-		
-		for _, paymentID := range paymentIDs {
-			t.Run("payment_"+paymentID, func(t *testing.T) {
-				refundID, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: paymentID})
-				
-				assert.NoError(t, err)
-				assert.NotEmpty(t, refundID)
-				assert.Contains(t, refundID, "ref_"+paymentID+"_")
-			})
-		}
+		assert.Contains(t, refund.RefundID, "ref_payment-123_")
+		assert.Equal(t, common.NewMoney(9999, "USD"), refund.Amount)
+		assert.True(t, refund.FullyRefunded())
+	})
+
+	t.Run("declined refund releases the balance", func(t *testing.T) {
+		fake := gateway.NewFake()
+		activities := chargedActivitiesWithGateway(t, fake, "payment-123", common.NewMoney(5000, "USD"))
+		fake.Script(gateway.OpRefund, gateway.Decline("card_closed"))
+
+		_, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", RefundID: "refund-1"})
+		assertErrorType(t, err, ErrTypePaymentDeclined)
+
+		refund, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", RefundID: "refund-1"})
+		require.NoError(t, err)
+		assert.True(t, refund.FullyRefunded())
+	})
+
+	t.Run("retry after a lost response does not refund twice", func(t *testing.T) {
+		fake := gateway.NewFake()
+		activities := chargedActivitiesWithGateway(t, fake, "payment-123", common.NewMoney(5000, "USD"))
+		fake.Script(gateway.OpRefund, gateway.TimeoutAfterProcessing())
+		req := ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(1000, "USD"), IdempotencyKey: "wf-1/run-1/1"}
+
+		_, err := activities.ProcessRefund(ctx, req)
+		assert.ErrorIs(t, err, gateway.ErrTimeout)
+
+		refund, err := activities.ProcessRefund(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, common.NewMoney(1000, "USD"), refund.Refunded)
+
+		// The gateway holds a single refund, leaving the rest refundable
+		_, err = activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(4000, "USD")})
+		assert.NoError(t, err)
+	})
+
+	t.Run("timed out refund is retried", func(t *testing.T) {
+		fake := gateway.NewFake()
+		activities := chargedActivitiesWithGateway(t, fake, "payment-123", common.NewMoney(5000, "USD"))
+		fake.Script(gateway.OpRefund, gateway.Timeout())
+
+		_, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
+		assert.ErrorIs(t, err, gateway.ErrTimeout)
+
+		_, err = activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, fake.Calls(gateway.OpRefund))
+	})
+
+	t.Run("partial refunds", func(t *testing.T) {
+		activities := chargedActivities(t, "payment-123", common.NewMoney(10000, "USD"))
+
+		first, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(2500, "USD"), Reason: "damaged item"})
+		require.NoError(t, err)
+		assert.Equal(t, common.NewMoney(2500, "USD"), first.Refunded)
+		assert.Equal(t, common.NewMoney(7500, "USD"), first.Remaining)
+		assert.False(t, first.FullyRefunded())
+
+		second, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(7500, "USD")})
+		require.NoError(t, err)
+		assert.Equal(t, common.NewMoney(10000, "USD"), second.Refunded)
+		assert.True(t, second.FullyRefunded())
+		assert.NotEqual(t, first.RefundID, second.RefundID)
+	})
+
+	t.Run("rejects over-refund", func(t *testing.T) {
+		activities := chargedActivities(t, "payment-123", common.NewMoney(5000, "USD"))
+
+		_, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(4000, "USD")})
+		require.NoError(t, err)
+
+		_, err = activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(1001, "USD")})
+		assertErrorType(t, err, ErrTypeOverRefund)
+
+		_, err = activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(1000, "USD")})
+		require.NoError(t, err)
+
+		// Nothing is left to refund
+		_, err = activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
+		assertErrorType(t, err, ErrTypeOverRefund)
+	})
+
+	t.Run("rejects currency mismatch", func(t *testing.T) {
+		activities := chargedActivities(t, "payment-123", common.NewMoney(5000, "USD"))
+
+		_, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(100, "EUR")})
+		assertErrorType(t, err, ErrTypeCurrencyMismatch)
+	})
+
+	t.Run("another worker refunds the charge", func(t *testing.T) {
+		// Replicas and restarted workers share only the gateway
+		fake := gateway.NewFake()
+		chargedActivitiesWithGateway(t, fake, "payment-123", common.NewMoney(5000, "USD"))
+		replica := NewActivities(fake)
+
+		refund, err := replica.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", RefundID: "refund-1", Amount: common.NewMoney(2000, "USD")})
+		require.NoError(t, err)
+		assert.Equal(t, common.NewMoney(3000, "USD"), refund.Remaining)
+
+		// A third worker sees the refund, by its ID and in the balance
+		again, err := NewActivities(fake).ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", RefundID: "refund-1", Amount: common.NewMoney(2000, "USD")})
+		require.NoError(t, err)
+		assert.Equal(t, refund, again)
+		assert.Equal(t, 1, fake.Calls(gateway.OpRefund))
+
+		_, err = NewActivities(fake).ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(3001, "USD")})
+		assertErrorType(t, err, ErrTypeOverRefund)
 	})
 
-	t.Run("generates unique refund IDs", func(t *testing.T) {
-		refundID1, err1 := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
-		refundID2, err2 := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123"})
-		
+	t.Run("rejects uncharged payment", func(t *testing.T) {
+		_, err := NewActivities(gateway.NewFake()).ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-unknown"})
+		assertErrorType(t, err, ErrTypePaymentNotCharged)
+	})
+
+	t.Run("deduplicates refund IDs", func(t *testing.T) {
+		activities := chargedActivities(t, "payment-123", common.NewMoney(5000, "USD"))
+		req := ProcessRefundRequest{PaymentID: "payment-123", RefundID: "refund-1", Amount: common.NewMoney(1000, "USD")}
+
+		first, err1 := activities.ProcessRefund(ctx, req)
+		second, err2 := activities.ProcessRefund(ctx, req)
+
 		assert.NoError(t, err1)
 		assert.NoError(t, err2)
-		assert.NotEqual(t, refundID1, refundID2)
+		assert.Equal(t, first, second)
+		assert.Equal(t, common.NewMoney(1000, "USD"), second.Refunded)
+	})
+
+	t.Run("concurrent refunds never exceed the charge", func(t *testing.T) {
+		activities := chargedActivities(t, "payment-123", common.NewMoney(1000, "USD"))
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded := 0
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := activities.ProcessRefund(ctx, ProcessRefundRequest{PaymentID: "payment-123", Amount: common.NewMoney(400, "USD")})
+				if err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 2, succeeded)
 	})
-}
\ No newline at end of file
+}
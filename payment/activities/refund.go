@@ -3,27 +3,96 @@ package activities
 import (
 	"context"
 	"fmt"
-	"log"
-	"time"
 
-	"github.com/google/uuid"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/gateway"
+	"go.temporal.io/sdk/temporal"
 )
 
 // ProcessRefundRequest represents the input for refund processing
 type ProcessRefundRequest struct {
-	PaymentID string `json:"paymentId"`
+	PaymentID string       `json:"paymentId"`
+	RefundID  string       `json:"refundId,omitempty"` // Deduplicates retried refunds
+	Amount    common.Money `json:"amount"`             // Zero refunds the remaining balance
+	Reason    string       `json:"reason,omitempty"`
+
+	IdempotencyKey string `json:"idempotencyKey,omitempty"` // Derived from the activity when empty
+}
+
+// RefundRecord describes a processed refund and the payment's refund totals
+type RefundRecord struct {
+	RefundID  string       `json:"refundId"`
+	Amount    common.Money `json:"amount"`
+	Refunded  common.Money `json:"refunded"`
+	Remaining common.Money `json:"remaining"`
 }
 
-func (a *Activities) ProcessRefund(ctx context.Context, req ProcessRefundRequest) (string, error) {
-	log.Printf("Processing refund for payment: %s", req.PaymentID)
-	
-	// Simulate refund processing
-	time.Sleep(400 * time.Millisecond)
-	
-	refundID := fmt.Sprintf("ref_%s_%s", req.PaymentID, uuid.New().String()[:8])
-	
-	// In a real implementation, you'd process refunds through payment gateways
-	log.Printf("Refund processed successfully. Refund ID: %s", refundID)
-	
-	return refundID, nil
-}
\ No newline at end of file
+// FullyRefunded reports whether nothing is left to refund on the payment
+func (r RefundRecord) FullyRefunded() bool {
+	return r.Remaining.IsZero()
+}
+
+// ProcessRefund refunds all or part of the payment's capture. The refund is
+// recorded on the gateway under its RefundID, or the idempotency key when
+// there is none, so repeating it returns the original record.
+func (a *Activities) ProcessRefund(ctx context.Context, req ProcessRefundRequest) (RefundRecord, error) {
+	logger(ctx).Info("Processing refund", "PaymentID", req.PaymentID, "RefundID", req.RefundID, "Amount", req.Amount.String(), "Reason", req.Reason)
+	key := idempotencyKey(ctx, req.IdempotencyKey)
+	reference := req.RefundID
+	if reference == "" {
+		reference = key
+	}
+
+	balance, err := a.loadRefundBalance(ctx, req.PaymentID)
+	if err != nil {
+		return RefundRecord{}, err
+	}
+	if refund, ok := balance.find(reference); reference != "" && ok {
+		record, _ := balance.record(refund.ID)
+		logger(ctx).Info("Refund already processed", "PaymentID", req.PaymentID, "RefundID", record.RefundID)
+		return record, nil
+	}
+
+	remaining, err := balance.remaining()
+	if err != nil {
+		return RefundRecord{}, err
+	}
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = remaining
+	}
+	cmp, err := amount.Cmp(remaining)
+	if err != nil {
+		return RefundRecord{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("refund of %s does not match charge currency %s", amount, balance.capture.Amount.Currency), ErrTypeCurrencyMismatch, err)
+	}
+	if !amount.IsPositive() || cmp > 0 {
+		return RefundRecord{}, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("refund of %s exceeds remaining balance %s of payment %s", amount, remaining, req.PaymentID), ErrTypeOverRefund, nil)
+	}
+
+	// The gateway rejects refunds exceeding the capture, so concurrent
+	// refunds from other workers cannot over-refund between the check and here
+	refund, err := a.gateway.Refund(ctx, gateway.RefundRequest{
+		CaptureID:      balance.capture.ID,
+		Amount:         amount,
+		Reason:         req.Reason,
+		Reference:      reference,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return RefundRecord{}, gatewayError("refund", err)
+	}
+
+	// Read the totals back, including refunds other workers made meanwhile
+	if balance, err = a.loadRefundBalance(ctx, req.PaymentID); err != nil {
+		return RefundRecord{}, err
+	}
+	record, ok := balance.record(refund.ID)
+	if !ok {
+		return RefundRecord{}, fmt.Errorf("refund %s of payment %s not found on the gateway", refund.ID, req.PaymentID)
+	}
+	logger(ctx).Info("Refund processed", "PaymentID", req.PaymentID, "RefundID", record.RefundID, "Refunded", record.Refunded.String(), "Remaining", record.Remaining.String())
+
+	return record, nil
+}
@@ -2,10 +2,19 @@ package activities
 
 import (
 	"context"
-	"log"
 	"time"
 )
 
+// Payment statuses recorded by UpdatePaymentStatus
+const (
+	PaymentStatusAuthorized        = "authorized"
+	PaymentStatusCompleted         = "completed"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusVoided            = "voided"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+)
+
 // UpdatePaymentStatusRequest represents the input for payment status updates
 type UpdatePaymentStatusRequest struct {
 	PaymentID string `json:"paymentId"`
@@ -13,13 +22,13 @@ type UpdatePaymentStatusRequest struct {
 }
 
 func (a *Activities) UpdatePaymentStatus(ctx context.Context, req UpdatePaymentStatusRequest) error {
-	log.Printf("Updating payment %s status to: %s", req.PaymentID, req.Status)
+	logger(ctx).Info("Updating payment status", "PaymentID", req.PaymentID, "Status", req.Status)
 	
 	// Simulate database update
 	time.Sleep(50 * time.Millisecond)
 	
 	// In a real implementation, you'd update your payment database
-	log.Printf("Payment %s status updated to: %s", req.PaymentID, req.Status)
+	logger(ctx).Info("Payment status updated", "PaymentID", req.PaymentID, "Status", req.Status)
 	
 	return nil
 }
\ No newline at end of file
@@ -6,10 +6,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"simple-temporal-workflow/payment/gateway"
 )
 
 func TestActivities_UpdatePaymentStatus(t *testing.T) {
-	activities := NewActivities()
+	activities := NewActivities(gateway.NewFake())
 	ctx := context.Background()
 
 	testCases := []struct {
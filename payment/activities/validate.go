@@ -3,25 +3,30 @@ package activities
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
+
+	"simple-temporal-workflow/common"
 )
 
 // ValidatePaymentRequest represents the input for payment validation
 type ValidatePaymentRequest struct {
-	PaymentID string  `json:"paymentId"`
-	Amount    float64 `json:"amount"`
+	PaymentID string       `json:"paymentId"`
+	Amount    common.Money `json:"amount"`
 }
 
 func (a *Activities) ValidatePayment(ctx context.Context, req ValidatePaymentRequest) (bool, error) {
-	log.Printf("Validating payment: %s for amount: %.2f", req.PaymentID, req.Amount)
+	logger(ctx).Info("Validating payment", "PaymentID", req.PaymentID, "Amount", req.Amount.String())
 	
 	// Simulate validation logic
 	if req.PaymentID == "" {
 		return false, fmt.Errorf("payment ID cannot be empty")
 	}
 	
-	if req.Amount <= 0 {
+	if err := req.Amount.Validate(); err != nil {
+		return false, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	if !req.Amount.IsPositive() {
 		return false, fmt.Errorf("amount must be positive")
 	}
 	
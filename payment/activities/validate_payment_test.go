@@ -6,21 +6,23 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"simple-temporal-workflow/payment/gateway"
+	"simple-temporal-workflow/common"
 )
 
 func TestActivities_ValidatePayment(t *testing.T) {
-	activities := NewActivities()
+	activities := NewActivities(gateway.NewFake())
 	ctx := context.Background()
 
 	t.Run("valid payment", func(t *testing.T) {
-		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: 99.99})
+		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")})
 		
 		assert.NoError(t, err)
 		assert.True(t, result)
 	})
 
 	t.Run("empty payment ID", func(t *testing.T) {
-		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "", Amount: 99.99})
+		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "", Amount: common.NewMoney(9999, "USD")})
 		
 		assert.Error(t, err)
 		assert.False(t, result)
@@ -28,7 +30,7 @@ func TestActivities_ValidatePayment(t *testing.T) {
 	})
 
 	t.Run("zero amount", func(t *testing.T) {
-		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: 0})
+		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(0, "USD")})
 		
 		assert.Error(t, err)
 		assert.False(t, result)
@@ -36,7 +38,7 @@ func TestActivities_ValidatePayment(t *testing.T) {
 	})
 
 	t.Run("negative amount", func(t *testing.T) {
-		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: -10.50})
+		result, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(-1050, "USD")})
 		
 		assert.Error(t, err)
 		assert.False(t, result)
@@ -45,7 +47,7 @@ func TestActivities_ValidatePayment(t *testing.T) {
 
 	t.Run("processing time", func(t *testing.T) {
 		start := time.Now()
-		_, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: 99.99})
+		_, err := activities.ValidatePayment(ctx, ValidatePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")})
 		elapsed := time.Since(start)
 		
 		assert.NoError(t, err)
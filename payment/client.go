@@ -3,7 +3,9 @@ package payment
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"simple-temporal-workflow/common"
 	"simple-temporal-workflow/payment/workflows"
 	temporalclient "go.temporal.io/sdk/client"
@@ -11,21 +13,52 @@ import (
 
 // ProcessPaymentRequest represents a request to process a payment
 type ProcessPaymentRequest struct {
-	PaymentID string  `json:"paymentId"`
-	Amount    float64 `json:"amount"`
-	UserID    string  `json:"userId,omitempty"` // Optional for search attributes
+	PaymentID string       `json:"paymentId" validate:"required,max=128"`
+	Amount    common.Money `json:"amount" validate:"required,positive"`
+	UserID    string       `json:"userId,omitempty" validate:"max=128"` // Optional for search attributes
 }
 
-// RefundPaymentRequest represents a request to refund a payment
+// RefundPaymentRequest represents a request to refund a payment. Without an
+// amount the remaining balance of the payment is refunded.
 type RefundPaymentRequest struct {
-	PaymentID string `json:"paymentId"`
-	UserID    string `json:"userId,omitempty"` // Optional for search attributes
+	PaymentID string       `json:"paymentId" validate:"required,max=128"`
+	Amount    common.Money `json:"amount,omitempty" validate:"positive"`
+	Reason    string       `json:"reason,omitempty" validate:"max=256"`
+	UserID    string       `json:"userId,omitempty" validate:"max=128"` // Optional for search attributes
+}
+
+// AuthorizePaymentRequest represents a request to authorize a payment for
+// later capture. The authorization is voided when no capture arrives within
+// CaptureTimeoutMinutes, seven days by default.
+type AuthorizePaymentRequest struct {
+	PaymentID             string       `json:"paymentId" validate:"required,max=128"`
+	Amount                common.Money `json:"amount" validate:"required,positive"`
+	CaptureTimeoutMinutes int          `json:"captureTimeoutMinutes,omitempty" validate:"min=1,max=43200"`
+	UserID                string       `json:"userId,omitempty" validate:"max=128"` // Optional for search attributes
+}
+
+// CapturePaymentRequest represents a request to capture an authorized payment.
+// Without an amount the full authorization is captured.
+type CapturePaymentRequest struct {
+	PaymentID string       `json:"paymentId" validate:"required,max=128"`
+	Amount    common.Money `json:"amount,omitempty" validate:"positive"`
+}
+
+// VoidPaymentRequest represents a request to void an authorized payment
+type VoidPaymentRequest struct {
+	PaymentID string `json:"paymentId" validate:"required,max=128"`
+	Reason    string `json:"reason,omitempty" validate:"max=256"`
 }
 
 // Client provides methods to execute payment workflows
 type Client interface {
 	ProcessPayment(ctx context.Context, req ProcessPaymentRequest) (*common.WorkflowResult, error)
 	RefundPayment(ctx context.Context, req RefundPaymentRequest) (*common.WorkflowResult, error)
+	AuthorizePayment(ctx context.Context, req AuthorizePaymentRequest) (*common.WorkflowResult, error)
+	CapturePayment(ctx context.Context, req CapturePaymentRequest) (*common.WorkflowResult, error)
+	VoidPayment(ctx context.Context, req VoidPaymentRequest) (*common.WorkflowResult, error)
+	GetProcessPaymentProgress(ctx context.Context, workflowID, runID string) (*workflows.ProcessPaymentProgress, error)
+	GetProcessPaymentState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error)
 }
 
 // paymentClient implements the Client interface
@@ -42,6 +75,10 @@ func NewClient(temporalClient temporalclient.Client, taskQueue string) Client {
 
 // ProcessPayment starts a ProcessPayment workflow
 func (c *paymentClient) ProcessPayment(ctx context.Context, req ProcessPaymentRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
 	workflowInput := workflows.PaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}
 	
 	// Build search attributes
@@ -51,7 +88,7 @@ func (c *paymentClient) ProcessPayment(ctx context.Context, req ProcessPaymentRe
 	}
 	
 	return c.commonClient.ExecuteWorkflow(ctx, common.WorkflowExecutionParams{
-		WorkflowType:     "ProcessPayment.v1",
+		WorkflowType:     ProcessPaymentWorkflow,
 		WorkflowIDPrefix: "process-payment",
 		WorkflowInput:    workflowInput,
 		SearchAttributes: searchAttributes,
@@ -59,9 +96,21 @@ func (c *paymentClient) ProcessPayment(ctx context.Context, req ProcessPaymentRe
 	})
 }
 
-// RefundPayment starts a RefundPayment workflow
+// RefundPayment starts a RefundPayment workflow. Refunds of a payment share
+// a workflow ID so they run one at a time; starting a refund while another is
+// in flight fails with WorkflowExecutionAlreadyStarted, unless it is a retry
+// carrying the idempotency key of that refund.
 func (c *paymentClient) RefundPayment(ctx context.Context, req RefundPaymentRequest) (*common.WorkflowResult, error) {
-	workflowInput := workflows.RefundRequest{PaymentID: req.PaymentID}
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workflowInput := workflows.RefundRequest{
+		PaymentID: req.PaymentID,
+		RefundID:  refundID(ctx),
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	}
 	
 	// Build search attributes
 	searchAttributes := make(map[string]any)
@@ -70,10 +119,115 @@ func (c *paymentClient) RefundPayment(ctx context.Context, req RefundPaymentRequ
 	}
 	
 	return c.commonClient.ExecuteWorkflow(ctx, common.WorkflowExecutionParams{
-		WorkflowType:     "RefundPayment.v1",
+		WorkflowType:     RefundPaymentWorkflow,
 		WorkflowIDPrefix: "refund-payment",
+		WorkflowID:       workflows.RefundWorkflowID(req.PaymentID),
 		WorkflowInput:    workflowInput,
 		SearchAttributes: searchAttributes,
 		SuccessMessage:   fmt.Sprintf("Payment refund workflow started for payment %s", req.PaymentID),
 	})
+}
+
+// AuthorizePayment starts an AuthorizeCapturePayment workflow. A payment
+// holds a single authorization at a time, so the workflow ID is derived from
+// the payment ID and capture and void requests address it by payment ID. A
+// retry carrying the idempotency key of the latest authorization returns it,
+// even once it has closed.
+func (c *paymentClient) AuthorizePayment(ctx context.Context, req AuthorizePaymentRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workflowInput := workflows.AuthorizeCaptureRequest{
+		PaymentID:      req.PaymentID,
+		Amount:         req.Amount,
+		CaptureTimeout: time.Duration(req.CaptureTimeoutMinutes) * time.Minute,
+	}
+
+	// Build search attributes
+	searchAttributes := make(map[string]any)
+	if req.UserID != "" {
+		searchAttributes["userId"] = req.UserID
+	}
+
+	return c.commonClient.ExecuteWorkflow(ctx, common.WorkflowExecutionParams{
+		WorkflowType:     AuthorizeCapturePaymentWorkflow,
+		WorkflowIDPrefix: "authorize-payment",
+		WorkflowID:       AuthorizationWorkflowID(req.PaymentID),
+		WorkflowInput:    workflowInput,
+		SearchAttributes: searchAttributes,
+		SuccessMessage:   fmt.Sprintf("Payment authorization workflow started for payment %s", req.PaymentID),
+	})
+}
+
+// CapturePayment signals the authorization workflow of a payment to capture it
+func (c *paymentClient) CapturePayment(ctx context.Context, req CapturePaymentRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workflowID := AuthorizationWorkflowID(req.PaymentID)
+	if err := c.commonClient.SignalWorkflow(ctx, workflowID, "", workflows.SignalCapture, workflows.CaptureSignal{Amount: req.Amount}); err != nil {
+		return nil, err
+	}
+
+	return &common.WorkflowResult{
+		WorkflowID: workflowID,
+		Message:    fmt.Sprintf("Capture requested for payment %s", req.PaymentID),
+	}, nil
+}
+
+// VoidPayment signals the authorization workflow of a payment to void it
+func (c *paymentClient) VoidPayment(ctx context.Context, req VoidPaymentRequest) (*common.WorkflowResult, error) {
+	if err := common.Validate(req); err != nil {
+		return nil, err
+	}
+
+	workflowID := AuthorizationWorkflowID(req.PaymentID)
+	if err := c.commonClient.SignalWorkflow(ctx, workflowID, "", workflows.SignalCancel, workflows.CancelSignal{Reason: req.Reason}); err != nil {
+		return nil, err
+	}
+
+	return &common.WorkflowResult{
+		WorkflowID: workflowID,
+		Message:    fmt.Sprintf("Void requested for payment %s", req.PaymentID),
+	}, nil
+}
+
+// AuthorizationWorkflowID returns the workflow ID holding a payment's authorization
+func AuthorizationWorkflowID(paymentID string) string {
+	return "authorize-payment-" + paymentID
+}
+
+// refundID identifies a refund so retries are not refunded twice. Requests
+// carrying an idempotency key reuse the same ID, others get a fresh one.
+func refundID(ctx context.Context) string {
+	key, ok := common.IdempotencyKeyFromContext(ctx)
+	if !ok {
+		return uuid.New().String()
+	}
+
+	var scope string
+	if principal, ok := common.PrincipalFromContext(ctx); ok {
+		scope = principal.Subject
+	}
+	return common.IdempotentWorkflowID("refund", key, scope)
+}
+
+// GetProcessPaymentProgress queries the progress of a running or completed ProcessPayment workflow
+func (c *paymentClient) GetProcessPaymentProgress(ctx context.Context, workflowID, runID string) (*workflows.ProcessPaymentProgress, error) {
+	var progress workflows.ProcessPaymentProgress
+	if err := c.commonClient.QueryWorkflow(ctx, workflowID, runID, common.QueryProgress, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// GetProcessPaymentState queries the compact state of a running or completed ProcessPayment workflow
+func (c *paymentClient) GetProcessPaymentState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error) {
+	var state common.WorkflowState
+	if err := c.commonClient.QueryWorkflow(ctx, workflowID, runID, common.QueryState, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
 }
\ No newline at end of file
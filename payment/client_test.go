@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/mocks"
+	"simple-temporal-workflow/api"
+	"simple-temporal-workflow/common"
+)
+
+func TestPaymentClient_AuthorizePayment(t *testing.T) {
+	req := AuthorizePaymentRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}
+
+	// A payment holds one authorization: a second start must fail rather
+	// than return the running workflow
+	isAuthorizationStart := mock.MatchedBy(func(options temporalclient.StartWorkflowOptions) bool {
+		return options.ID == AuthorizationWorkflowID("payment-123") && options.WorkflowExecutionErrorWhenAlreadyStarted
+	})
+
+	t.Run("starts the authorization workflow", func(t *testing.T) {
+		run := &mocks.WorkflowRun{}
+		run.On("GetID").Return(AuthorizationWorkflowID("payment-123"))
+		run.On("GetRunID").Return("run-1")
+		temporalClient := &mocks.Client{}
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isAuthorizationStart, AuthorizeCapturePaymentWorkflow, mock.Anything).Return(run, nil)
+
+		result, err := NewClient(temporalClient, "queue").AuthorizePayment(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, AuthorizationWorkflowID("payment-123"), result.WorkflowID)
+	})
+
+	t.Run("duplicate authorization conflicts", func(t *testing.T) {
+		temporalClient := &mocks.Client{}
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isAuthorizationStart, AuthorizeCapturePaymentWorkflow, mock.Anything).
+			Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "run-1"))
+
+		server := api.NewServer(&Domain{client: NewClient(temporalClient, "queue")})
+		mux := http.NewServeMux()
+		server.RegisterRoutes(mux)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/workflows/payment/authorize", strings.NewReader(`{"paymentId":"payment-123","amount":{"minorUnits":9999,"currency":"USD"}}`))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "workflow_already_started")
+	})
+
+	// Retrying with the Idempotency-Key of an authorization that already
+	// closed returns it rather than authorizing the payment again
+	t.Run("retry after completion replays the authorization", func(t *testing.T) {
+		var memo map[string]any
+		temporalClient := &mocks.Client{}
+		temporalClient.On("DescribeWorkflowExecution", mock.Anything, AuthorizationWorkflowID("payment-123"), "").
+			Return(func(ctx context.Context, workflowID, runID string) *workflowservice.DescribeWorkflowExecutionResponse {
+				if memo == nil {
+					return nil
+				}
+				key, err := converter.GetDefaultDataConverter().ToPayload(memo[common.IdempotencyMemoKey])
+				require.NoError(t, err)
+				return &workflowservice.DescribeWorkflowExecutionResponse{
+					WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+						Execution: &commonpb.WorkflowExecution{WorkflowId: workflowID, RunId: "run-1"},
+						Status:    enums.WORKFLOW_EXECUTION_STATUS_COMPLETED,
+						Memo:      &commonpb.Memo{Fields: map[string]*commonpb.Payload{common.IdempotencyMemoKey: key}},
+					},
+				}
+			}, func(ctx context.Context, workflowID, runID string) error {
+				if memo == nil {
+					return serviceerror.NewNotFound("workflow not found")
+				}
+				return nil
+			})
+		temporalClient.On("ExecuteWorkflow", mock.Anything, isAuthorizationStart, AuthorizeCapturePaymentWorkflow, mock.Anything).
+			Return(func(ctx context.Context, options temporalclient.StartWorkflowOptions, workflow interface{}, args ...interface{}) temporalclient.WorkflowRun {
+				memo = options.Memo
+				run := &mocks.WorkflowRun{}
+				run.On("GetID").Return(options.ID)
+				run.On("GetRunID").Return("run-1")
+				return run
+			}, nil).Once()
+
+		server := api.NewServer(&Domain{client: NewClient(temporalClient, "queue")})
+		mux := http.NewServeMux()
+		require.NoError(t, server.RegisterRoutes(mux))
+		authorize := func() *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPost, "/api/workflows/payment/authorize", strings.NewReader(`{"paymentId":"payment-123","amount":{"minorUnits":9999,"currency":"USD"}}`))
+			r.Header.Set(api.IdempotencyKeyHeader, "authorize-once")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			return w
+		}
+
+		assert.Equal(t, http.StatusCreated, authorize().Code)
+		w := authorize()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"runId":"run-1"`)
+		temporalClient.AssertNumberOfCalls(t, "ExecuteWorkflow", 1)
+	})
+}
@@ -0,0 +1,96 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-temporal-workflow/api"
+	"simple-temporal-workflow/domain"
+	"simple-temporal-workflow/payment/activities"
+	"simple-temporal-workflow/payment/gateway"
+	"simple-temporal-workflow/payment/workflows"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+// Scopes required by the payment endpoints
+const (
+	ScopeWrite  = "payment:write"
+	ScopeRefund = "payment:refund"
+)
+
+func init() {
+	domain.Register(NewDomain())
+}
+
+// Domain wires the payment workflows, activities and client into the service
+type Domain struct {
+	orchestrator *Orchestrator
+	gateway      gateway.PaymentGateway
+	client       Client
+}
+
+// NewDomain creates the payment domain. No processor is integrated yet, so
+// payments go through the in-process fake gateway.
+func NewDomain() *Domain {
+	fake := gateway.NewFake()
+	fake.Latency = 200 * time.Millisecond
+	return NewDomainWithGateway(fake)
+}
+
+// NewDomainWithGateway creates the payment domain charging through gw
+func NewDomainWithGateway(gw gateway.PaymentGateway) *Domain {
+	paymentActivities := activities.NewActivities(gw)
+	paymentWorkflows := NewWorkflows(paymentActivities)
+
+	return &Domain{
+		orchestrator: NewOrchestrator(paymentWorkflows, paymentActivities),
+		gateway:      gw,
+	}
+}
+
+// Name returns the domain name
+func (d *Domain) Name() string {
+	return "payment"
+}
+
+// Register registers the payment workflows and activities with the worker
+func (d *Domain) Register(w worker.Worker) {
+	d.orchestrator.RegisterWithWorker(w)
+}
+
+// CheckHealth reports whether the payment gateway the activities charge
+// through is reachable
+func (d *Domain) CheckHealth(ctx context.Context) error {
+	if err := d.gateway.Ping(ctx); err != nil {
+		return fmt.Errorf("payment gateway unreachable: %w", err)
+	}
+	return nil
+}
+
+// NewClient creates the payment workflow client
+func (d *Domain) NewClient(temporalClient client.Client, taskQueue string) {
+	d.client = NewClient(temporalClient, taskQueue)
+}
+
+// Client returns the payment workflow client created by NewClient
+func (d *Domain) Client() Client {
+	return d.client
+}
+
+// Routes registers the payment workflow-trigger endpoints, failing route
+// registration when NewClient was not called as the handlers are bound to
+// the client
+func (d *Domain) Routes(router *api.Router) {
+	if d.client == nil {
+		router.Fail(errors.New("payment domain routes registered before NewClient"))
+		return
+	}
+	router.Handle("/api/workflows/payment/process", ScopeWrite, api.WorkflowHandler("ProcessPayment", d.client.ProcessPayment))
+	router.Handle("/api/workflows/payment/refund", ScopeRefund, api.WorkflowHandler("RefundPayment", d.client.RefundPayment))
+	router.Handle("/api/workflows/payment/authorize", ScopeWrite, api.WorkflowHandler("AuthorizeCapturePayment", d.client.AuthorizePayment))
+	router.Handle("/api/workflows/payment/capture", ScopeWrite, api.SignalHandler(workflows.SignalCapture, d.client.CapturePayment))
+	router.Handle("/api/workflows/payment/void", ScopeWrite, api.SignalHandler(workflows.SignalCancel, d.client.VoidPayment))
+}
@@ -0,0 +1,101 @@
+package payment
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-temporal-workflow/api"
+	"simple-temporal-workflow/domain"
+	"simple-temporal-workflow/metrics"
+	"simple-temporal-workflow/payment/gateway"
+	"simple-temporal-workflow/worker"
+)
+
+func TestDomain_Routes(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"process payment", "/api/workflows/payment/process", `{"paymentId":"payment-123","amount":{"minorUnits":9999,"currency":"USD"}}`, http.StatusCreated},
+		{"process payment with legacy amount", "/api/workflows/payment/process", `{"paymentId":"payment-123","amount":99.99}`, http.StatusCreated},
+		{"process payment without amount", "/api/workflows/payment/process", `{"paymentId":"payment-123"}`, http.StatusBadRequest},
+		{"process payment with negative amount", "/api/workflows/payment/process", `{"paymentId":"payment-123","amount":{"minorUnits":-100,"currency":"USD"}}`, http.StatusBadRequest},
+		{"process payment with unsupported currency", "/api/workflows/payment/process", `{"paymentId":"payment-123","amount":{"minorUnits":100,"currency":"XYZ"}}`, http.StatusBadRequest},
+		{"refund payment", "/api/workflows/payment/refund", `{"paymentId":"payment-123"}`, http.StatusCreated},
+		{"partial refund", "/api/workflows/payment/refund", `{"paymentId":"payment-123","amount":{"minorUnits":2500,"currency":"USD"},"reason":"damaged item"}`, http.StatusCreated},
+		{"refund payment with negative amount", "/api/workflows/payment/refund", `{"paymentId":"payment-123","amount":{"minorUnits":-2500,"currency":"USD"}}`, http.StatusBadRequest},
+		{"refund payment without payment ID", "/api/workflows/payment/refund", `{}`, http.StatusBadRequest},
+		{"authorize payment", "/api/workflows/payment/authorize", `{"paymentId":"payment-123","amount":{"minorUnits":9999,"currency":"USD"},"captureTimeoutMinutes":60}`, http.StatusCreated},
+		{"authorize payment with invalid timeout", "/api/workflows/payment/authorize", `{"paymentId":"payment-123","amount":{"minorUnits":9999,"currency":"USD"},"captureTimeoutMinutes":-1}`, http.StatusBadRequest},
+		{"capture payment", "/api/workflows/payment/capture", `{"paymentId":"payment-123"}`, http.StatusAccepted},
+		{"void payment", "/api/workflows/payment/void", `{"paymentId":"payment-123","reason":"order cancelled"}`, http.StatusAccepted},
+		{"void payment without payment ID", "/api/workflows/payment/void", `{}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paymentClient := &stubClient{}
+			server := api.NewServer(&Domain{client: paymentClient})
+			mux := http.NewServeMux()
+			server.RegisterRoutes(mux)
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.wantStatus, w.Code)
+			if tc.wantStatus == http.StatusBadRequest {
+				assert.Nil(t, paymentClient.processed)
+				assert.Nil(t, paymentClient.refunded)
+				assert.Nil(t, paymentClient.authorized)
+				assert.Nil(t, paymentClient.voided)
+			}
+		})
+	}
+}
+
+func TestDomain_RoutesWithoutClient(t *testing.T) {
+	server := api.NewServer(NewDomain())
+
+	err := server.RegisterRoutes(http.NewServeMux())
+	assert.EqualError(t, err, "payment domain routes registered before NewClient")
+}
+
+// The gateway is checked by the ready endpoint, as wired for domains
+// implementing domain.HealthChecker
+func TestDomain_CheckHealth(t *testing.T) {
+	fake := gateway.NewFake()
+	var checker domain.HealthChecker = NewDomainWithGateway(fake)
+	healthServer := worker.NewHealthServer(":0", metrics.NewRegistry(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	healthServer.AddHealthCheck("payment", checker.CheckHealth)
+
+	ready := func() (int, worker.ReadinessReport) {
+		w := httptest.NewRecorder()
+		healthServer.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var report worker.ReadinessReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return w.Code, report
+	}
+
+	status, report := ready()
+	assert.Equal(t, http.StatusOK, status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "payment", report.Checks[0].Name)
+	assert.Equal(t, worker.CheckPass, report.Checks[0].Status)
+
+	fake.Script(gateway.OpPing, gateway.Timeout())
+	status, report = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "payment", report.Checks[0].Name)
+	assert.Equal(t, worker.CheckFail, report.Checks[0].Status)
+	assert.Equal(t, "payment gateway unreachable: gateway timed out: ping", report.Checks[0].Error)
+}
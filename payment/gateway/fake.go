@@ -0,0 +1,314 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"simple-temporal-workflow/common"
+)
+
+// Operation names a gateway call that can be scripted on the fake
+type Operation string
+
+const (
+	OpAuthorize Operation = "authorize"
+	OpCapture   Operation = "capture"
+	OpVoid      Operation = "void"
+	OpRefund    Operation = "refund"
+	OpPing      Operation = "ping"
+)
+
+type outcomeKind int
+
+const (
+	outcomeApprove outcomeKind = iota
+	outcomeDecline
+	outcomeTimeout
+	outcomeDuplicate
+	outcomeLostResponse
+)
+
+// Outcome is the scripted response of a single fake gateway call
+type Outcome struct {
+	kind   outcomeKind
+	reason string
+}
+
+// Approve processes the call normally
+func Approve() Outcome {
+	return Outcome{kind: outcomeApprove}
+}
+
+// Decline rejects the call with ErrDeclined and the given reason
+func Decline(reason string) Outcome {
+	return Outcome{kind: outcomeDecline, reason: reason}
+}
+
+// Timeout fails the call with ErrTimeout without processing it
+func Timeout() Outcome {
+	return Outcome{kind: outcomeTimeout}
+}
+
+// TimeoutAfterProcessing processes the call but fails it with ErrTimeout, as
+// when the gateway's response is lost. Retrying with the same idempotency key
+// returns the processed transaction.
+func TimeoutAfterProcessing() Outcome {
+	return Outcome{kind: outcomeLostResponse}
+}
+
+// Duplicate returns the last transaction created by the same operation for
+// the same payment, flagged as a duplicate, without processing the call
+func Duplicate() Outcome {
+	return Outcome{kind: outcomeDuplicate}
+}
+
+// Fake is an in-process PaymentGateway. Calls are approved unless outcomes
+// are scripted for the operation, which are consumed one call at a time.
+// Requests repeating an idempotency key return the original transaction
+// without consuming a scripted outcome.
+type Fake struct {
+	// Latency is added to every call to simulate network round trips
+	Latency time.Duration
+
+	mu           sync.Mutex
+	transactions map[string]Transaction
+	byPayment    map[string][]string // Transaction IDs in creation order
+	last         map[string]Transaction // keyed by operation and payment
+	idempotent   map[string]Transaction // keyed by operation and idempotency key
+	captured     map[string]common.Money
+	refunded     map[string]common.Money
+	voided       map[string]bool
+	scripts      map[Operation][]Outcome
+	calls        map[Operation]int
+}
+
+// NewFake creates a fake gateway approving every call
+func NewFake() *Fake {
+	return &Fake{
+		transactions: make(map[string]Transaction),
+		byPayment:    make(map[string][]string),
+		last:         make(map[string]Transaction),
+		idempotent:   make(map[string]Transaction),
+		captured:     make(map[string]common.Money),
+		refunded:     make(map[string]common.Money),
+		voided:       make(map[string]bool),
+		scripts:      make(map[Operation][]Outcome),
+		calls:        make(map[Operation]int),
+	}
+}
+
+// Script queues outcomes for the next calls of op
+func (f *Fake) Script(op Operation, outcomes ...Outcome) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.scripts[op] = append(f.scripts[op], outcomes...)
+}
+
+// Calls returns how many times op was called
+func (f *Fake) Calls(op Operation) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[op]
+}
+
+// Authorize implements PaymentGateway
+func (f *Fake) Authorize(ctx context.Context, req AuthorizeRequest) (Transaction, error) {
+	return f.call(ctx, OpAuthorize, req.PaymentID, req.IdempotencyKey, func() (Transaction, error) {
+		if !req.Amount.IsPositive() {
+			return Transaction{}, fmt.Errorf("%w: amount must be positive", ErrDeclined)
+		}
+		return f.record(Transaction{PaymentID: req.PaymentID, Type: TransactionAuthorization, Amount: req.Amount}, "auth"), nil
+	})
+}
+
+// Capture implements PaymentGateway
+func (f *Fake) Capture(ctx context.Context, req CaptureRequest) (Transaction, error) {
+	return f.call(ctx, OpCapture, f.paymentOf(req.AuthorizationID), req.IdempotencyKey, func() (Transaction, error) {
+		auth, err := f.lookup(req.AuthorizationID, TransactionAuthorization)
+		if err != nil {
+			return Transaction{}, err
+		}
+		if f.voided[auth.ID] {
+			return Transaction{}, fmt.Errorf("%w: authorization %s is voided", ErrInvalidTransaction, auth.ID)
+		}
+		if _, ok := f.captured[auth.ID]; ok {
+			return Transaction{}, fmt.Errorf("%w: authorization %s is already captured", ErrInvalidTransaction, auth.ID)
+		}
+
+		amount := req.Amount
+		if amount.IsZero() {
+			amount = auth.Amount
+		}
+		if cmp, err := amount.Cmp(auth.Amount); err != nil || cmp > 0 || !amount.IsPositive() {
+			return Transaction{}, fmt.Errorf("%w: cannot capture %s of authorization for %s", ErrInvalidTransaction, amount, auth.Amount)
+		}
+
+		f.captured[auth.ID] = amount
+		return f.record(Transaction{PaymentID: auth.PaymentID, Type: TransactionCapture, Amount: amount, ParentID: auth.ID}, "txn"), nil
+	})
+}
+
+// Void implements PaymentGateway
+func (f *Fake) Void(ctx context.Context, req VoidRequest) (Transaction, error) {
+	return f.call(ctx, OpVoid, f.paymentOf(req.AuthorizationID), req.IdempotencyKey, func() (Transaction, error) {
+		auth, err := f.lookup(req.AuthorizationID, TransactionAuthorization)
+		if err != nil {
+			return Transaction{}, err
+		}
+		if _, ok := f.captured[auth.ID]; ok {
+			return Transaction{}, fmt.Errorf("%w: authorization %s is already captured", ErrInvalidTransaction, auth.ID)
+		}
+		if f.voided[auth.ID] {
+			return Transaction{}, fmt.Errorf("%w: authorization %s is already voided", ErrInvalidTransaction, auth.ID)
+		}
+
+		f.voided[auth.ID] = true
+		return f.record(Transaction{PaymentID: auth.PaymentID, Type: TransactionVoid, Amount: auth.Amount, ParentID: auth.ID}, "void"), nil
+	})
+}
+
+// Refund implements PaymentGateway
+func (f *Fake) Refund(ctx context.Context, req RefundRequest) (Transaction, error) {
+	return f.call(ctx, OpRefund, f.paymentOf(req.CaptureID), req.IdempotencyKey, func() (Transaction, error) {
+		capture, err := f.lookup(req.CaptureID, TransactionCapture)
+		if err != nil {
+			return Transaction{}, err
+		}
+
+		refunded, ok := f.refunded[capture.ID]
+		if !ok {
+			refunded = common.NewMoney(0, capture.Amount.Currency)
+		}
+		total, err := refunded.Add(req.Amount)
+		if err != nil {
+			return Transaction{}, fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+		}
+		if cmp, _ := total.Cmp(capture.Amount); cmp > 0 || !req.Amount.IsPositive() {
+			return Transaction{}, fmt.Errorf("%w: cannot refund %s of capture for %s", ErrInvalidTransaction, req.Amount, capture.Amount)
+		}
+
+		f.refunded[capture.ID] = total
+		return f.record(Transaction{PaymentID: capture.PaymentID, Type: TransactionRefund, Amount: req.Amount, ParentID: capture.ID, Reference: req.Reference}, "ref"), nil
+	})
+}
+
+// GetTransaction implements PaymentGateway
+func (f *Fake) GetTransaction(ctx context.Context, transactionID string) (Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	txn, ok := f.transactions[transactionID]
+	if !ok {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	return txn, nil
+}
+
+// ListTransactions implements PaymentGateway
+func (f *Fake) ListTransactions(ctx context.Context, paymentID string) ([]Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	transactions := make([]Transaction, 0, len(f.byPayment[paymentID]))
+	for _, id := range f.byPayment[paymentID] {
+		transactions = append(transactions, f.transactions[id])
+	}
+	return transactions, nil
+}
+
+// Ping implements PaymentGateway. Scripted declines and timeouts fail it.
+func (f *Fake) Ping(ctx context.Context) error {
+	_, err := f.call(ctx, OpPing, "", "", func() (Transaction, error) {
+		return Transaction{}, nil
+	})
+	return err
+}
+
+// call deduplicates by idempotency key and applies the next scripted outcome
+// of op before running process
+func (f *Fake) call(ctx context.Context, op Operation, paymentID, idempotencyKey string, process func() (Transaction, error)) (Transaction, error) {
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-ctx.Done():
+			return Transaction{}, fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[op]++
+	dedupeKey := ""
+	if idempotencyKey != "" {
+		dedupeKey = string(op) + "/" + idempotencyKey
+		if txn, ok := f.idempotent[dedupeKey]; ok {
+			txn.Duplicate = true
+			return txn, nil
+		}
+	}
+
+	outcome := Approve()
+	if queued := f.scripts[op]; len(queued) > 0 {
+		outcome, f.scripts[op] = queued[0], queued[1:]
+	}
+
+	switch outcome.kind {
+	case outcomeDecline:
+		return Transaction{}, fmt.Errorf("%w: %s", ErrDeclined, outcome.reason)
+	case outcomeTimeout:
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTimeout, op)
+	case outcomeDuplicate:
+		if txn, ok := f.last[string(op)+"/"+paymentID]; ok {
+			txn.Duplicate = true
+			return txn, nil
+		}
+	}
+
+	txn, err := process()
+	if err != nil {
+		return Transaction{}, err
+	}
+	f.last[string(op)+"/"+paymentID] = txn
+	if dedupeKey != "" {
+		f.idempotent[dedupeKey] = txn
+	}
+
+	if outcome.kind == outcomeLostResponse {
+		return Transaction{}, fmt.Errorf("%w: %s response lost", ErrTimeout, op)
+	}
+	return txn, nil
+}
+
+// record stores a new transaction. Callers must hold f.mu.
+func (f *Fake) record(txn Transaction, prefix string) Transaction {
+	txn.ID = fmt.Sprintf("%s_%s_%s", prefix, txn.PaymentID, uuid.New().String()[:8])
+	f.transactions[txn.ID] = txn
+	f.byPayment[txn.PaymentID] = append(f.byPayment[txn.PaymentID], txn.ID)
+	return txn
+}
+
+// lookup finds a transaction of the given type. Callers must hold f.mu.
+func (f *Fake) lookup(transactionID string, txnType TransactionType) (Transaction, error) {
+	txn, ok := f.transactions[transactionID]
+	if !ok {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	if txn.Type != txnType {
+		return Transaction{}, fmt.Errorf("%w: %s is not a %s", ErrInvalidTransaction, transactionID, txnType)
+	}
+	return txn, nil
+}
+
+// paymentOf returns the payment a transaction belongs to
+func (f *Fake) paymentOf(transactionID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.transactions[transactionID].PaymentID
+}
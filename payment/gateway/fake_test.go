@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"simple-temporal-workflow/common"
+)
+
+func TestFake_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	auth, err := fake.Authorize(ctx, AuthorizeRequest{PaymentID: "payment-123", Amount: common.NewMoney(1000, "USD")})
+	require.NoError(t, err)
+	assert.Equal(t, TransactionAuthorization, auth.Type)
+
+	capture, err := fake.Capture(ctx, CaptureRequest{AuthorizationID: auth.ID})
+	require.NoError(t, err)
+	assert.Equal(t, auth.ID, capture.ParentID)
+	assert.Equal(t, common.NewMoney(1000, "USD"), capture.Amount)
+
+	_, err = fake.Void(ctx, VoidRequest{AuthorizationID: auth.ID})
+	assert.ErrorIs(t, err, ErrInvalidTransaction)
+
+	refund, err := fake.Refund(ctx, RefundRequest{CaptureID: capture.ID, Amount: common.NewMoney(600, "USD"), Reference: "refund-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "refund-1", refund.Reference)
+	_, err = fake.Refund(ctx, RefundRequest{CaptureID: capture.ID, Amount: common.NewMoney(500, "USD")})
+	assert.ErrorIs(t, err, ErrInvalidTransaction)
+
+	stored, err := fake.GetTransaction(ctx, capture.ID)
+	require.NoError(t, err)
+	assert.Equal(t, capture, stored)
+
+	_, err = fake.GetTransaction(ctx, "txn_unknown")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	transactions, err := fake.ListTransactions(ctx, "payment-123")
+	require.NoError(t, err)
+	assert.Equal(t, []Transaction{auth, capture, refund}, transactions)
+
+	transactions, err = fake.ListTransactions(ctx, "payment-unknown")
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestFake_Void(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	auth, err := fake.Authorize(ctx, AuthorizeRequest{PaymentID: "payment-123", Amount: common.NewMoney(1000, "USD")})
+	require.NoError(t, err)
+
+	_, err = fake.Void(ctx, VoidRequest{AuthorizationID: auth.ID})
+	require.NoError(t, err)
+
+	_, err = fake.Capture(ctx, CaptureRequest{AuthorizationID: auth.ID})
+	assert.ErrorIs(t, err, ErrInvalidTransaction)
+}
+
+func TestFake_Script(t *testing.T) {
+	ctx := context.Background()
+	req := AuthorizeRequest{PaymentID: "payment-123", Amount: common.NewMoney(1000, "USD")}
+
+	fake := NewFake()
+	fake.Script(OpAuthorize, Decline("insufficient_funds"), Timeout(), Approve(), Duplicate())
+
+	_, err := fake.Authorize(ctx, req)
+	assert.ErrorIs(t, err, ErrDeclined)
+	assert.Contains(t, err.Error(), "insufficient_funds")
+
+	_, err = fake.Authorize(ctx, req)
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	approved, err := fake.Authorize(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, approved.Duplicate)
+
+	duplicate, err := fake.Authorize(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, duplicate.Duplicate)
+	assert.Equal(t, approved.ID, duplicate.ID)
+
+	// Unscripted calls are approved
+	next, err := fake.Authorize(ctx, req)
+	require.NoError(t, err)
+	assert.NotEqual(t, approved.ID, next.ID)
+	assert.Equal(t, 5, fake.Calls(OpAuthorize))
+}
+
+func TestFake_LatencyHonorsContext(t *testing.T) {
+	fake := NewFake()
+	fake.Latency = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := fake.Authorize(ctx, AuthorizeRequest{PaymentID: "payment-123", Amount: common.NewMoney(1000, "USD")})
+	assert.ErrorIs(t, err, ErrTimeout)
+}
@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+
+	"simple-temporal-workflow/common"
+)
+
+// Gateway errors, wrapped with details by implementations
+var (
+	ErrDeclined            = errors.New("payment declined")
+	ErrTimeout             = errors.New("gateway timed out")
+	ErrTransactionNotFound = errors.New("transaction not found")
+	ErrInvalidTransaction  = errors.New("invalid transaction")
+)
+
+// TransactionType identifies the operation that created a transaction
+type TransactionType string
+
+const (
+	TransactionAuthorization TransactionType = "authorization"
+	TransactionCapture       TransactionType = "capture"
+	TransactionVoid          TransactionType = "void"
+	TransactionRefund        TransactionType = "refund"
+)
+
+// Transaction is a gateway record of an authorization, capture, void or refund
+type Transaction struct {
+	ID        string          `json:"id"`
+	PaymentID string          `json:"paymentId"`
+	Type      TransactionType `json:"type"`
+	Amount    common.Money    `json:"amount"`
+	ParentID  string          `json:"parentId,omitempty"` // Authorization or capture the transaction applies to
+	Reference string          `json:"reference,omitempty"` // Merchant reference of a refund
+
+	// Duplicate is set when the gateway recognised the request as a repeat
+	// and returned the original transaction instead of creating a new one
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// Requests carry an IdempotencyKey: repeating a request with the same key
+// returns the original transaction, flagged Duplicate, instead of processing
+// it again. An empty key disables deduplication.
+
+// AuthorizeRequest reserves funds for a payment
+type AuthorizeRequest struct {
+	PaymentID      string
+	Amount         common.Money
+	IdempotencyKey string
+}
+
+// CaptureRequest settles all or part of an authorization
+type CaptureRequest struct {
+	AuthorizationID string
+	Amount          common.Money
+	IdempotencyKey  string
+}
+
+// VoidRequest releases an uncaptured authorization
+type VoidRequest struct {
+	AuthorizationID string
+	IdempotencyKey  string
+}
+
+// RefundRequest returns all or part of a capture to the payer
+type RefundRequest struct {
+	CaptureID      string
+	Amount         common.Money
+	Reason         string
+	Reference      string // Recorded on the refund to find it again
+	IdempotencyKey string
+}
+
+// PaymentGateway is the payment processor used by the payment activities
+type PaymentGateway interface {
+	Authorize(ctx context.Context, req AuthorizeRequest) (Transaction, error)
+	Capture(ctx context.Context, req CaptureRequest) (Transaction, error)
+	Void(ctx context.Context, req VoidRequest) (Transaction, error)
+	Refund(ctx context.Context, req RefundRequest) (Transaction, error)
+	GetTransaction(ctx context.Context, transactionID string) (Transaction, error)
+
+	// ListTransactions returns the transactions of a payment in the order
+	// they were made, none for an unknown payment
+	ListTransactions(ctx context.Context, paymentID string) ([]Transaction, error)
+
+	// Ping checks that the processor is reachable
+	Ping(ctx context.Context) error
+}
@@ -12,12 +12,16 @@ import (
 type Workflows interface {
 	ProcessPayment(ctx workflow.Context, req workflows.PaymentRequest) (string, error)
 	RefundPayment(ctx workflow.Context, req workflows.RefundRequest) (bool, error)
+	AuthorizeCapturePayment(ctx workflow.Context, req workflows.AuthorizeCaptureRequest) (string, error)
 }
 
 // Activities defines the payment activity interface
 type Activities interface {
 	ValidatePayment(ctx context.Context, req activities.ValidatePaymentRequest) (bool, error)
 	ChargePayment(ctx context.Context, req activities.ChargePaymentRequest) (string, error)
-	ProcessRefund(ctx context.Context, req activities.ProcessRefundRequest) (string, error)
+	AuthorizePayment(ctx context.Context, req activities.AuthorizePaymentRequest) (string, error)
+	CapturePayment(ctx context.Context, req activities.CapturePaymentRequest) (string, error)
+	VoidPayment(ctx context.Context, req activities.VoidPaymentRequest) error
+	ProcessRefund(ctx context.Context, req activities.ProcessRefundRequest) (activities.RefundRecord, error)
 	UpdatePaymentStatus(ctx context.Context, req activities.UpdatePaymentStatusRequest) error
 }
\ No newline at end of file
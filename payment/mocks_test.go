@@ -0,0 +1,50 @@
+package payment
+
+import (
+	"context"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/workflows"
+)
+
+// stubClient records the requests each payment workflow was started with
+type stubClient struct {
+	processed  *ProcessPaymentRequest
+	refunded   *RefundPaymentRequest
+	authorized *AuthorizePaymentRequest
+	captured   *CapturePaymentRequest
+	voided     *VoidPaymentRequest
+}
+
+func (c *stubClient) ProcessPayment(ctx context.Context, req ProcessPaymentRequest) (*common.WorkflowResult, error) {
+	c.processed = &req
+	return &common.WorkflowResult{WorkflowID: "process-payment-1", RunID: "run-1"}, nil
+}
+
+func (c *stubClient) RefundPayment(ctx context.Context, req RefundPaymentRequest) (*common.WorkflowResult, error) {
+	c.refunded = &req
+	return &common.WorkflowResult{WorkflowID: "refund-payment-1", RunID: "run-1"}, nil
+}
+
+func (c *stubClient) AuthorizePayment(ctx context.Context, req AuthorizePaymentRequest) (*common.WorkflowResult, error) {
+	c.authorized = &req
+	return &common.WorkflowResult{WorkflowID: AuthorizationWorkflowID(req.PaymentID), RunID: "run-1"}, nil
+}
+
+func (c *stubClient) CapturePayment(ctx context.Context, req CapturePaymentRequest) (*common.WorkflowResult, error) {
+	c.captured = &req
+	return &common.WorkflowResult{WorkflowID: AuthorizationWorkflowID(req.PaymentID)}, nil
+}
+
+func (c *stubClient) VoidPayment(ctx context.Context, req VoidPaymentRequest) (*common.WorkflowResult, error) {
+	c.voided = &req
+	return &common.WorkflowResult{WorkflowID: AuthorizationWorkflowID(req.PaymentID)}, nil
+}
+
+func (c *stubClient) GetProcessPaymentProgress(ctx context.Context, workflowID, runID string) (*workflows.ProcessPaymentProgress, error) {
+	return &workflows.ProcessPaymentProgress{}, nil
+}
+
+func (c *stubClient) GetProcessPaymentState(ctx context.Context, workflowID, runID string) (*common.WorkflowState, error) {
+	return &common.WorkflowState{}, nil
+}
@@ -1,10 +1,19 @@
 package payment
 
 import (
+	"simple-temporal-workflow/payment/child"
 	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
 )
 
+// Workflow types registered by the payment domain, declared with the child
+// stubs
+const (
+	ProcessPaymentWorkflow          = child.ProcessPaymentWorkflow
+	RefundPaymentWorkflow           = child.RefundPaymentWorkflow
+	AuthorizeCapturePaymentWorkflow = child.AuthorizeCapturePaymentWorkflow
+)
+
 type Orchestrator struct {
 	workflows  Workflows
 	activities Activities
@@ -20,15 +29,21 @@ func NewOrchestrator(workflows Workflows, activities Activities) *Orchestrator {
 func (o *Orchestrator) RegisterWithWorker(w worker.Worker) {
 	// Register workflows with versioning
 	w.RegisterWorkflowWithOptions(o.workflows.ProcessPayment, workflow.RegisterOptions{
-		Name: "ProcessPayment.v1",
+		Name: ProcessPaymentWorkflow,
 	})
 	w.RegisterWorkflowWithOptions(o.workflows.RefundPayment, workflow.RegisterOptions{
-		Name: "RefundPayment.v1",
+		Name: RefundPaymentWorkflow,
+	})
+	w.RegisterWorkflowWithOptions(o.workflows.AuthorizeCapturePayment, workflow.RegisterOptions{
+		Name: AuthorizeCapturePaymentWorkflow,
 	})
 
 	// Register activities
 	w.RegisterActivity(o.activities.ValidatePayment)
 	w.RegisterActivity(o.activities.ChargePayment)
+	w.RegisterActivity(o.activities.AuthorizePayment)
+	w.RegisterActivity(o.activities.CapturePayment)
+	w.RegisterActivity(o.activities.VoidPayment)
 	w.RegisterActivity(o.activities.ProcessRefund)
 	w.RegisterActivity(o.activities.UpdatePaymentStatus)
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package payment
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/workflows"
+	"simple-temporal-workflow/replay"
+)
+
+// TestReplay replays the recorded histories in testdata against the
+// workflows the payment domain registers
+func TestReplay(t *testing.T) {
+	replay.New(NewDomain().Register).Run(t, "testdata")
+}
+
+// Payments started before amounts became Money carry a float amount in
+// their input, which must still decode and replay
+func TestReplay_BaselineFloatAmount(t *testing.T) {
+	const path = "testdata/process_payment_v1_baseline_float_amount.json"
+	require.NoError(t, replay.New(NewDomain().Register).ReplayFile(path))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	history, err := client.HistoryFromJSON(file, client.HistoryJSONOptions{})
+	require.NoError(t, err)
+	input := history.Events[0].GetWorkflowExecutionStartedEventAttributes().GetInput()
+	var req workflows.PaymentRequest
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(input, &req))
+	assert.Equal(t, common.NewMoney(2999, common.DefaultCurrency), req.Amount)
+}
@@ -18,6 +18,10 @@ func (a *paymentWorkflowAdapter) RefundPayment(ctx workflow.Context, req workflo
 	return a.workflows.RefundPayment(ctx, req)
 }
 
+func (a *paymentWorkflowAdapter) AuthorizeCapturePayment(ctx workflow.Context, req workflows.AuthorizeCaptureRequest) (string, error) {
+	return a.workflows.AuthorizeCapturePayment(ctx, req)
+}
+
 // NewWorkflows creates a new payment workflows service
 func NewWorkflows(activities Activities) Workflows {
 	return &paymentWorkflowAdapter{
@@ -0,0 +1,251 @@
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/activities"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Signals accepted by AuthorizeCapturePayment while awaiting capture
+const (
+	SignalCapture = "capture"
+	SignalCancel  = "cancel"
+)
+
+// DefaultCaptureTimeout bounds how long an authorization is held without a
+// capture signal when the request does not set CaptureTimeout
+const DefaultCaptureTimeout = 7 * 24 * time.Hour
+
+// AuthorizeCapturePayment steps reported through the progress query
+const (
+	StepAuthorizePayment = "authorize_payment"
+	StepAwaitCapture     = "await_capture"
+	StepVoidPayment      = "void_payment"
+)
+
+// AuthorizeCaptureRequest represents an authorize-then-capture workflow input
+type AuthorizeCaptureRequest struct {
+	PaymentID      string        `json:"paymentId"`
+	Amount         common.Money  `json:"amount"`
+	CaptureTimeout time.Duration `json:"captureTimeout,omitempty"`
+}
+
+// CaptureSignal requests capture of the authorization
+type CaptureSignal struct {
+	Amount common.Money `json:"amount"` // Zero captures the authorized amount
+}
+
+// CancelSignal requests the authorization be voided
+type CancelSignal struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// AuthorizeCaptureProgress represents the progress of an AuthorizeCapturePayment workflow
+type AuthorizeCaptureProgress struct {
+	common.Progress
+	AuthorizationID string `json:"authorizationId,omitempty"`
+	TransactionID   string `json:"transactionId,omitempty"`
+	Voided          bool   `json:"voided,omitempty"`
+
+	// CaptureError is why the last capture was rejected or failed, while
+	// the authorization waits for another one
+	CaptureError string `json:"captureError,omitempty"`
+}
+
+// AuthorizeCapturePayment authorizes the payment, then waits for a capture
+// signal. The authorization is voided when the capture timeout expires, a
+// cancel signal arrives or the workflow is cancelled, and only then.
+func (w *Workflows) AuthorizeCapturePayment(ctx workflow.Context, req AuthorizeCaptureRequest) (string, error) {
+	// Expose intermediate state through query handlers
+	var progress AuthorizeCaptureProgress
+	err := common.SetProgressQueryHandlers(ctx,
+		func() AuthorizeCaptureProgress { return progress },
+		progress.State,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to register query handlers: %w", err)
+	}
+
+	// Apply default activity options
+	ctx = common.WithActivityOptions(ctx)
+
+	// Step 1: Validate payment
+	progress.StartStep(StepValidatePayment)
+	var isValid bool
+	err = workflow.ExecuteActivity(ctx, w.activities.ValidatePayment, activities.ValidatePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Get(ctx, &isValid)
+	if err != nil {
+		err = fmt.Errorf("failed to validate payment: %w", err)
+		progress.Fail(err)
+		return "", err
+	}
+	if !isValid {
+		err = fmt.Errorf("payment validation failed for payment %s", req.PaymentID)
+		progress.Fail(err)
+		return "", err
+	}
+	progress.CompleteStep()
+
+	// Step 2: Authorize payment
+	progress.StartStep(StepAuthorizePayment)
+	err = workflow.ExecuteActivity(ctx, w.activities.AuthorizePayment, activities.AuthorizePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Get(ctx, &progress.AuthorizationID)
+	if err != nil {
+		err = fmt.Errorf("failed to authorize payment: %w", err)
+		progress.Fail(err)
+		w.updatePaymentStatus(ctx, req.PaymentID, activities.PaymentStatusFailed)
+		return "", err
+	}
+	if err := w.updatePaymentStatus(ctx, req.PaymentID, activities.PaymentStatusAuthorized); err != nil {
+		err = fmt.Errorf("failed to update payment status: %w", err)
+		progress.Fail(err)
+		return "", w.void(ctx, req.PaymentID, &progress, err)
+	}
+	progress.CompleteStep()
+
+	// Step 3: Wait for a capture, cancellation or the capture timeout. An
+	// invalid capture signal or a failed capture keeps the authorization,
+	// which waits for another capture until the timeout.
+	progress.StartStep(StepAwaitCapture)
+	captureTimeout := req.CaptureTimeout
+	if captureTimeout <= 0 {
+		captureTimeout = DefaultCaptureTimeout
+	}
+	deadline := workflow.Now(ctx).Add(captureTimeout)
+	for {
+		capture, voidReason, workflowErr := awaitCapture(ctx, deadline.Sub(workflow.Now(ctx)), captureTimeout)
+		if capture == nil {
+			progress.CompleteStep()
+			logger(ctx).Info("Voiding authorization", "PaymentID", req.PaymentID, "Reason", voidReason)
+			if err := w.void(ctx, req.PaymentID, &progress, workflowErr); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Payment %s authorization voided: %s", req.PaymentID, voidReason), nil
+		}
+		if err := validateCapture(req.Amount, capture.Amount); err != nil {
+			logger(ctx).Warn("Ignoring capture signal", "PaymentID", req.PaymentID, "Error", err)
+			progress.CaptureError = err.Error()
+			continue
+		}
+
+		err = workflow.ExecuteActivity(ctx, w.activities.CapturePayment, activities.CapturePaymentRequest{
+			PaymentID:       req.PaymentID,
+			AuthorizationID: progress.AuthorizationID,
+			Amount:          capture.Amount,
+		}).Get(ctx, &progress.TransactionID)
+		if err != nil {
+			err = fmt.Errorf("failed to capture payment: %w", err)
+			logger(ctx).Warn("Capture failed, holding the authorization", "PaymentID", req.PaymentID, "Error", err)
+			progress.CaptureError = err.Error()
+			continue
+		}
+		progress.CaptureError = ""
+		progress.CompleteStep()
+		break
+	}
+
+	// Step 4: Update payment status
+	progress.StartStep(StepUpdatePaymentStatus)
+	if err := w.updatePaymentStatus(ctx, req.PaymentID, activities.PaymentStatusCompleted); err != nil {
+		err = fmt.Errorf("failed to update payment status: %w", err)
+		progress.Fail(err)
+		return "", err
+	}
+	progress.CompleteStep()
+
+	return fmt.Sprintf("Payment %s captured successfully. Transaction: %s", req.PaymentID, progress.TransactionID), nil
+}
+
+// awaitCapture waits up to timeout for a capture or cancel signal. Without
+// a capture, it returns why the authorization is voided, and the error the
+// workflow fails with when it was cancelled.
+func awaitCapture(ctx workflow.Context, timeout, captureTimeout time.Duration) (*CaptureSignal, string, error) {
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+
+	var (
+		capture     *CaptureSignal
+		voidReason  string
+		workflowErr error
+	)
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(workflow.GetSignalChannel(ctx, SignalCapture), func(c workflow.ReceiveChannel, more bool) {
+		var signal CaptureSignal
+		c.Receive(ctx, &signal)
+		capture = &signal
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, SignalCancel), func(c workflow.ReceiveChannel, more bool) {
+		var signal CancelSignal
+		c.Receive(ctx, &signal)
+		voidReason = "cancelled"
+		if signal.Reason != "" {
+			voidReason = "cancelled: " + signal.Reason
+		}
+	})
+	selector.AddFuture(workflow.NewTimer(timerCtx, max(timeout, 0)), func(f workflow.Future) {
+		if err := f.Get(timerCtx, nil); err != nil {
+			// The timer only fails when the workflow itself is cancelled
+			workflowErr = err
+			voidReason = "workflow cancelled"
+			return
+		}
+		voidReason = fmt.Sprintf("capture timeout of %s expired", captureTimeout)
+	})
+	selector.Select(ctx)
+	return capture, voidReason, workflowErr
+}
+
+// validateCapture checks a requested capture against the authorized amount
+func validateCapture(authorized, amount common.Money) error {
+	if amount.IsZero() {
+		return nil
+	}
+	cmp, err := amount.Cmp(authorized)
+	if err != nil {
+		return fmt.Errorf("capture of %s does not match the authorized currency %s", amount, authorized.Currency)
+	}
+	if !amount.IsPositive() || cmp > 0 {
+		return fmt.Errorf("cannot capture %s of an authorization for %s", amount, authorized)
+	}
+	return nil
+}
+
+// void releases the authorization and marks the payment voided, returning
+// cause, or the void failure when the authorization could not be released.
+// It runs on a disconnected context so it still completes after the
+// workflow is cancelled.
+func (w *Workflows) void(ctx workflow.Context, paymentID string, progress *AuthorizeCaptureProgress, cause error) error {
+	if temporal.IsCanceledError(ctx.Err()) {
+		ctx, _ = workflow.NewDisconnectedContext(ctx)
+	}
+
+	progress.StartStep(StepVoidPayment)
+	err := workflow.ExecuteActivity(ctx, w.activities.VoidPayment, activities.VoidPaymentRequest{PaymentID: paymentID, AuthorizationID: progress.AuthorizationID}).Get(ctx, nil)
+	if err != nil {
+		logger(ctx).Error("Failed to void authorization", "PaymentID", paymentID, "AuthorizationID", progress.AuthorizationID, "Error", err)
+		err = errors.Join(cause, fmt.Errorf("failed to void authorization %s: %w", progress.AuthorizationID, err))
+		progress.Fail(err)
+		return err
+	}
+	progress.Voided = true
+
+	if err := w.updatePaymentStatus(ctx, paymentID, activities.PaymentStatusVoided); err != nil {
+		err = errors.Join(cause, fmt.Errorf("failed to update payment status: %w", err))
+		progress.Fail(err)
+		return err
+	}
+	if cause != nil {
+		progress.Fail(cause)
+		return cause
+	}
+	progress.CompleteStep()
+	return nil
+}
+
+// updatePaymentStatus records the payment status
+func (w *Workflows) updatePaymentStatus(ctx workflow.Context, paymentID, status string) error {
+	return workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: paymentID, Status: status}).Get(ctx, nil)
+}
@@ -0,0 +1,226 @@
+package workflows
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"simple-temporal-workflow/common"
+	"simple-temporal-workflow/payment/activities"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// AuthorizeCapturePaymentTestSuite defines the test suite for AuthorizeCapturePayment workflow
+type AuthorizeCapturePaymentTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func TestAuthorizeCapturePaymentTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthorizeCapturePaymentTestSuite))
+}
+
+// newAuthorizedEnv returns an environment whose payment is validated and authorized
+func (s *AuthorizeCapturePaymentTestSuite) newAuthorizedEnv(req AuthorizeCaptureRequest) (*testsuite.TestWorkflowEnvironment, *MockActivities) {
+	env := s.NewTestWorkflowEnvironment()
+	mockActivities := &MockActivities{}
+
+	env.OnActivity(mockActivities.ValidatePayment, mock.Anything, activities.ValidatePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return(true, nil)
+	env.OnActivity(mockActivities.AuthorizePayment, mock.Anything, activities.AuthorizePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return("auth-123", nil)
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "authorized"}).Return(nil)
+
+	return env, mockActivities
+}
+
+// expectVoid registers the void of the authorization
+func expectVoid(env *testsuite.TestWorkflowEnvironment, mockActivities *MockActivities, paymentID string) {
+	env.OnActivity(mockActivities.VoidPayment, mock.Anything, activities.VoidPaymentRequest{PaymentID: paymentID, AuthorizationID: "auth-123"}).Return(nil).Once()
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: paymentID, Status: "voided"}).Return(nil).Once()
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_Capture() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD"), CaptureTimeout: time.Hour}
+	env, mockActivities := s.newAuthorizedEnv(req)
+
+	// Capture part of the authorization
+	captureAmount := common.NewMoney(5000, "USD")
+	env.OnActivity(mockActivities.CapturePayment, mock.Anything, activities.CapturePaymentRequest{PaymentID: req.PaymentID, AuthorizationID: "auth-123", Amount: captureAmount}).Return("txn-456", nil)
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "completed"}).Return(nil)
+
+	env.RegisterDelayedCallback(func() {
+		// The workflow is holding the authorization
+		result, err := env.QueryWorkflow(common.QueryProgress)
+		s.NoError(err)
+		var progress AuthorizeCaptureProgress
+		s.NoError(result.Get(&progress))
+		s.Equal(StepAwaitCapture, progress.CurrentStep)
+		s.Equal("auth-123", progress.AuthorizationID)
+
+		env.SignalWorkflow(SignalCapture, CaptureSignal{Amount: captureAmount})
+	}, 10*time.Minute)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Contains(result, "txn-456")
+	mockActivities.AssertNotCalled(s.T(), "VoidPayment", mock.Anything, mock.Anything)
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_CaptureTimeout() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD"), CaptureTimeout: time.Hour}
+	env, mockActivities := s.newAuthorizedEnv(req)
+	expectVoid(env, mockActivities, req.PaymentID)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Contains(result, "capture timeout of 1h0m0s expired")
+	mockActivities.AssertExpectations(s.T())
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_CancelSignal() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}
+	env, mockActivities := s.newAuthorizedEnv(req)
+	expectVoid(env, mockActivities, req.PaymentID)
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCancel, CancelSignal{Reason: "order cancelled"})
+	}, time.Hour)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Contains(result, "cancelled: order cancelled")
+	mockActivities.AssertExpectations(s.T())
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_WorkflowCancelled() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}
+	env, mockActivities := s.newAuthorizedEnv(req)
+	expectVoid(env, mockActivities, req.PaymentID)
+
+	env.RegisterDelayedCallback(env.CancelWorkflow, time.Hour)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	mockActivities.AssertExpectations(s.T())
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_RejectsInvalidCapture() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD"), CaptureTimeout: time.Hour}
+	env, mockActivities := s.newAuthorizedEnv(req)
+	env.OnActivity(mockActivities.CapturePayment, mock.Anything, activities.CapturePaymentRequest{PaymentID: req.PaymentID, AuthorizationID: "auth-123", Amount: common.NewMoney(9999, "USD")}).Return("txn-456", nil).Once()
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "completed"}).Return(nil)
+
+	// Captures above the authorization or in another currency are ignored,
+	// only the valid one reaches the CapturePayment mock
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCapture, CaptureSignal{Amount: common.NewMoney(10000, "USD")})
+	}, 10*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCapture, CaptureSignal{Amount: common.NewMoney(9999, "EUR")})
+	}, 20*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		result, err := env.QueryWorkflow(common.QueryProgress)
+		s.NoError(err)
+		var progress AuthorizeCaptureProgress
+		s.NoError(result.Get(&progress))
+		s.Equal(StepAwaitCapture, progress.CurrentStep)
+		s.Contains(progress.CaptureError, "does not match the authorized currency")
+		s.False(progress.Voided)
+
+		env.SignalWorkflow(SignalCapture, CaptureSignal{Amount: common.NewMoney(9999, "USD")})
+	}, 30*time.Minute)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Contains(result, "txn-456")
+	mockActivities.AssertNotCalled(s.T(), "VoidPayment", mock.Anything, mock.Anything)
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_CaptureFailureHoldsAuthorization() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD"), CaptureTimeout: 2 * time.Hour}
+	env, mockActivities := s.newAuthorizedEnv(req)
+	expectVoid(env, mockActivities, req.PaymentID)
+	env.OnActivity(mockActivities.CapturePayment, mock.Anything, mock.Anything).Return("", errors.New("processor unavailable"))
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCapture, CaptureSignal{})
+	}, time.Hour)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	// The authorization is held until the capture timeout
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	var result string
+	s.NoError(env.GetWorkflowResult(&result))
+	s.Contains(result, "capture timeout of 2h0m0s expired")
+	mockActivities.AssertExpectations(s.T())
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_CaptureRetriedAfterFailure() {
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD"), CaptureTimeout: 2 * time.Hour}
+	env, mockActivities := s.newAuthorizedEnv(req)
+	env.OnActivity(mockActivities.CapturePayment, mock.Anything, mock.Anything).Return("", errors.New("processor unavailable")).Once()
+	env.OnActivity(mockActivities.CapturePayment, mock.Anything, mock.Anything).Return("txn-456", nil).Once()
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "completed"}).Return(nil)
+
+	// An invalid capture and a failed capture before the one that succeeds
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCapture, CaptureSignal{Amount: common.NewMoney(10000, "USD")})
+	}, 10*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCapture, CaptureSignal{})
+	}, 20*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(SignalCapture, CaptureSignal{})
+	}, time.Hour)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	result, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress AuthorizeCaptureProgress
+	s.NoError(result.Get(&progress))
+	s.Equal([]string{StepValidatePayment, StepAuthorizePayment, StepAwaitCapture, StepUpdatePaymentStatus}, progress.CompletedSteps)
+	s.Equal("txn-456", progress.TransactionID)
+	s.Empty(progress.CaptureError)
+	mockActivities.AssertExpectations(s.T())
+}
+
+func (s *AuthorizeCapturePaymentTestSuite) TestAuthorizeCapturePayment_AuthorizationDeclined() {
+	env := s.NewTestWorkflowEnvironment()
+	mockActivities := &MockActivities{}
+	req := AuthorizeCaptureRequest{PaymentID: "payment-123", Amount: common.NewMoney(9999, "USD")}
+
+	env.OnActivity(mockActivities.ValidatePayment, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(mockActivities.AuthorizePayment, mock.Anything, mock.Anything).Return("", errors.New("card declined"))
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "failed"}).Return(nil)
+
+	env.ExecuteWorkflow(NewWorkflows(mockActivities).AuthorizeCapturePayment, req)
+
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "failed to authorize payment")
+	mockActivities.AssertNotCalled(s.T(), "VoidPayment", mock.Anything, mock.Anything)
+}
@@ -22,11 +22,26 @@ func (m *MockActivities) ChargePayment(ctx context.Context, req activities.Charg
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockActivities) ProcessRefund(ctx context.Context, req activities.ProcessRefundRequest) (string, error) {
+func (m *MockActivities) AuthorizePayment(ctx context.Context, req activities.AuthorizePaymentRequest) (string, error) {
 	args := m.Called(ctx, req)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockActivities) CapturePayment(ctx context.Context, req activities.CapturePaymentRequest) (string, error) {
+	args := m.Called(ctx, req)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockActivities) VoidPayment(ctx context.Context, req activities.VoidPaymentRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockActivities) ProcessRefund(ctx context.Context, req activities.ProcessRefundRequest) (activities.RefundRecord, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(activities.RefundRecord), args.Error(1)
+}
+
 func (m *MockActivities) UpdatePaymentStatus(ctx context.Context, req activities.UpdatePaymentStatusRequest) error {
 	args := m.Called(ctx, req)
 	return args.Error(0)
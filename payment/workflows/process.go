@@ -11,46 +11,85 @@ import (
 
 // PaymentRequest represents a payment workflow input
 type PaymentRequest struct {
-	PaymentID string  `json:"paymentId"`
-	Amount    float64 `json:"amount"`
+	PaymentID string       `json:"paymentId"`
+	Amount    common.Money `json:"amount"`
+}
+
+// ProcessPayment steps reported through the progress query
+const (
+	StepValidatePayment     = "validate_payment"
+	StepChargePayment       = "charge_payment"
+	StepUpdatePaymentStatus = "update_payment_status"
+)
+
+// ProcessPaymentProgress represents the progress of a ProcessPayment workflow
+type ProcessPaymentProgress struct {
+	common.Progress
+	TransactionID string `json:"transactionId,omitempty"`
 }
 
 // Activities interface for payment activities
 type Activities interface {
 	ValidatePayment(ctx context.Context, req activities.ValidatePaymentRequest) (bool, error)
 	ChargePayment(ctx context.Context, req activities.ChargePaymentRequest) (string, error)
-	ProcessRefund(ctx context.Context, req activities.ProcessRefundRequest) (string, error)
+	AuthorizePayment(ctx context.Context, req activities.AuthorizePaymentRequest) (string, error)
+	CapturePayment(ctx context.Context, req activities.CapturePaymentRequest) (string, error)
+	VoidPayment(ctx context.Context, req activities.VoidPaymentRequest) error
+	ProcessRefund(ctx context.Context, req activities.ProcessRefundRequest) (activities.RefundRecord, error)
 	UpdatePaymentStatus(ctx context.Context, req activities.UpdatePaymentStatusRequest) error
 }
 
 func (w *Workflows) ProcessPayment(ctx workflow.Context, req PaymentRequest) (string, error) {
+	// Expose intermediate state through query handlers
+	var progress ProcessPaymentProgress
+	err := common.SetProgressQueryHandlers(ctx,
+		func() ProcessPaymentProgress { return progress },
+		progress.State,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to register query handlers: %w", err)
+	}
+
 	// Apply default activity options
 	ctx = common.WithActivityOptions(ctx)
 
 	// Step 1: Validate payment
+	progress.StartStep(StepValidatePayment)
 	var isValid bool
-	err := workflow.ExecuteActivity(ctx, w.activities.ValidatePayment, activities.ValidatePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Get(ctx, &isValid)
+	err = workflow.ExecuteActivity(ctx, w.activities.ValidatePayment, activities.ValidatePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Get(ctx, &isValid)
 	if err != nil {
-		return "", fmt.Errorf("failed to validate payment: %w", err)
+		err = fmt.Errorf("failed to validate payment: %w", err)
+		progress.Fail(err)
+		return "", err
 	}
 	if !isValid {
-		return "", fmt.Errorf("payment validation failed for payment %s", req.PaymentID)
+		err = fmt.Errorf("payment validation failed for payment %s", req.PaymentID)
+		progress.Fail(err)
+		return "", err
 	}
+	progress.CompleteStep()
 
 	// Step 2: Charge payment
-	var transactionID string
-	err = workflow.ExecuteActivity(ctx, w.activities.ChargePayment, activities.ChargePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Get(ctx, &transactionID)
+	progress.StartStep(StepChargePayment)
+	err = workflow.ExecuteActivity(ctx, w.activities.ChargePayment, activities.ChargePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Get(ctx, &progress.TransactionID)
 	if err != nil {
+		err = fmt.Errorf("failed to charge payment: %w", err)
+		progress.Fail(err)
 		// Update payment status to failed
-		workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "failed"}).Get(ctx, nil)
-		return "", fmt.Errorf("failed to charge payment: %w", err)
+		workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: activities.PaymentStatusFailed}).Get(ctx, nil)
+		return "", err
 	}
+	progress.CompleteStep()
 
 	// Step 3: Update payment status
-	err = workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "completed"}).Get(ctx, nil)
+	progress.StartStep(StepUpdatePaymentStatus)
+	err = workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: activities.PaymentStatusCompleted}).Get(ctx, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to update payment status: %w", err)
+		err = fmt.Errorf("failed to update payment status: %w", err)
+		progress.Fail(err)
+		return "", err
 	}
+	progress.CompleteStep()
 
-	return fmt.Sprintf("Payment %s processed successfully. Transaction: %s", req.PaymentID, transactionID), nil
+	return fmt.Sprintf("Payment %s processed successfully. Transaction: %s", req.PaymentID, progress.TransactionID), nil
 }
\ No newline at end of file
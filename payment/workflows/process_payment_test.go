@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"simple-temporal-workflow/common"
 	"simple-temporal-workflow/payment/activities"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -28,7 +29,7 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_Success() {
 	
 	req := PaymentRequest{
 		PaymentID: "payment-123",
-		Amount:    99.99,
+		Amount:    common.NewMoney(9999, "USD"),
 	}
 	
 	// Register mock activities with the test environment
@@ -58,7 +59,7 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_ValidationFailure() {
 	
 	req := PaymentRequest{
 		PaymentID: "invalid-payment",
-		Amount:    -10.0,
+		Amount:    common.NewMoney(-1000, "USD"),
 	}
 	
 	// Register mock activities with the test environment
@@ -82,7 +83,7 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_ValidationError() {
 	
 	req := PaymentRequest{
 		PaymentID: "payment-123",
-		Amount:    99.99,
+		Amount:    common.NewMoney(9999, "USD"),
 	}
 	validationError := errors.New("payment service unavailable")
 	
@@ -107,7 +108,7 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_ChargeFailureWithCompensati
 	
 	req := PaymentRequest{
 		PaymentID: "payment-123",
-		Amount:    99.99,
+		Amount:    common.NewMoney(9999, "USD"),
 	}
 	chargeError := errors.New("insufficient funds")
 	
@@ -135,7 +136,7 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_StatusUpdateFailure() {
 	
 	req := PaymentRequest{
 		PaymentID: "payment-123",
-		Amount:    99.99,
+		Amount:    common.NewMoney(9999, "USD"),
 	}
 	statusError := errors.New("database connection lost")
 	
@@ -158,11 +159,12 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_DifferentAmounts() {
 	testCases := []struct {
 		name      string
 		paymentID string
-		amount    float64
+		amount    common.Money
 	}{
-		{"small amount", "payment-small", 1.00},
-		{"large amount", "payment-large", 9999.99},
-		{"decimal amount", "payment-decimal", 123.45},
+		{"small amount", "payment-small", common.NewMoney(100, "USD")},
+		{"large amount", "payment-large", common.NewMoney(999999, "USD")},
+		{"decimal amount", "payment-decimal", common.NewMoney(12345, "USD")},
+		{"zero-decimal currency", "payment-jpy", common.NewMoney(5000, "JPY")},
 	}
 
 	for _, tc := range testCases {
@@ -194,4 +196,75 @@ func (s *ProcessPaymentTestSuite) TestProcessPayment_DifferentAmounts() {
 			s.Contains(result, tc.paymentID)
 		})
 	}
+}
+
+func (s *ProcessPaymentTestSuite) TestProcessPayment_ProgressQuery() {
+	env := s.NewTestWorkflowEnvironment()
+	
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+	
+	req := PaymentRequest{
+		PaymentID: "payment-123",
+		Amount:    common.NewMoney(9999, "USD"),
+	}
+	
+	// Register mock activities with the test environment
+	env.OnActivity(mockActivities.ValidatePayment, mock.Anything, activities.ValidatePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return(true, nil)
+	env.OnActivity(mockActivities.ChargePayment, mock.Anything, activities.ChargePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return("txn-456", nil)
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "completed"}).Return(nil)
+	
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.ProcessPayment, req)
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	
+	// Verify the progress query
+	value, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress ProcessPaymentProgress
+	s.NoError(value.Get(&progress))
+	s.Equal([]string{StepValidatePayment, StepChargePayment, StepUpdatePaymentStatus}, progress.CompletedSteps)
+	s.Equal("txn-456", progress.TransactionID)
+	s.Empty(progress.LastError)
+	
+	// Verify the state query
+	value, err = env.QueryWorkflow(common.QueryState)
+	s.NoError(err)
+	var state common.WorkflowState
+	s.NoError(value.Get(&state))
+	s.Equal(common.StateCompleted, state.Status)
+}
+
+func (s *ProcessPaymentTestSuite) TestProcessPayment_ProgressQueryAfterChargeFailure() {
+	env := s.NewTestWorkflowEnvironment()
+	
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+	
+	req := PaymentRequest{
+		PaymentID: "payment-123",
+		Amount:    common.NewMoney(9999, "USD"),
+	}
+	chargeError := errors.New("insufficient funds")
+	
+	// Register mock activities with the test environment
+	env.OnActivity(mockActivities.ValidatePayment, mock.Anything, activities.ValidatePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return(true, nil)
+	env.OnActivity(mockActivities.ChargePayment, mock.Anything, activities.ChargePaymentRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return("", chargeError)
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "failed"}).Return(nil)
+	
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.ProcessPayment, req)
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	
+	// Verify the progress query
+	value, err := env.QueryWorkflow(common.QueryProgress)
+	s.NoError(err)
+	var progress ProcessPaymentProgress
+	s.NoError(value.Get(&progress))
+	s.Equal(StepChargePayment, progress.CurrentStep)
+	s.Equal([]string{StepValidatePayment}, progress.CompletedSteps)
+	s.Empty(progress.TransactionID)
+	s.Contains(progress.LastError, "insufficient funds")
 }
\ No newline at end of file
@@ -10,25 +10,44 @@ import (
 
 // RefundRequest represents a refund workflow input
 type RefundRequest struct {
-	PaymentID string `json:"paymentId"`
+	PaymentID string       `json:"paymentId"`
+	RefundID  string       `json:"refundId,omitempty"` // Deduplicates retried refunds
+	Amount    common.Money `json:"amount"`             // Zero refunds the remaining balance
+	Reason    string       `json:"reason,omitempty"`
+}
+
+// RefundWorkflowID returns the workflow ID shared by every refund of a
+// payment, so refunds started through the API and by other workflows are
+// serialized against each other
+func RefundWorkflowID(paymentID string) string {
+	return "refund-payment-" + paymentID
 }
 
 func (w *Workflows) RefundPayment(ctx workflow.Context, req RefundRequest) (bool, error) {
 	// Apply default activity options
 	ctx = common.WithActivityOptions(ctx)
 
-	// Step 1: Process refund
-	var refundID string
-	err := workflow.ExecuteActivity(ctx, w.activities.ProcessRefund, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Get(ctx, &refundID)
+	// Step 1: Process refund against the remaining balance of the charge
+	var refund activities.RefundRecord
+	err := workflow.ExecuteActivity(ctx, w.activities.ProcessRefund, activities.ProcessRefundRequest{
+		PaymentID: req.PaymentID,
+		RefundID:  req.RefundID,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	}).Get(ctx, &refund)
 	if err != nil {
 		return false, fmt.Errorf("failed to process refund: %w", err)
 	}
 
 	// Step 2: Update payment status
-	err = workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "refunded"}).Get(ctx, nil)
+	status := activities.PaymentStatusPartiallyRefunded
+	if refund.FullyRefunded() {
+		status = activities.PaymentStatusRefunded
+	}
+	err = workflow.ExecuteActivity(ctx, w.activities.UpdatePaymentStatus, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: status}).Get(ctx, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to update payment status: %w", err)
 	}
 
 	return true, nil
-}
\ No newline at end of file
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"testing"
 
+	"simple-temporal-workflow/common"
 	"simple-temporal-workflow/payment/activities"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
 )
 
@@ -20,6 +22,16 @@ func TestRefundPaymentTestSuite(t *testing.T) {
 	suite.Run(t, new(RefundPaymentTestSuite))
 }
 
+// fullRefund returns a refund record leaving nothing to refund
+func fullRefund(refundID string) activities.RefundRecord {
+	return activities.RefundRecord{
+		RefundID:  refundID,
+		Amount:    common.NewMoney(9999, "USD"),
+		Refunded:  common.NewMoney(9999, "USD"),
+		Remaining: common.NewMoney(0, "USD"),
+	}
+}
+
 func (s *RefundPaymentTestSuite) TestRefundPayment_Success() {
 	env := s.NewTestWorkflowEnvironment()
 	
@@ -31,7 +43,7 @@ func (s *RefundPaymentTestSuite) TestRefundPayment_Success() {
 	}
 	
 	// Register mock activities with the test environment
-	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return("refund-456", nil)
+	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return(fullRefund("refund-456"), nil)
 	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "refunded"}).Return(nil)
 	
 	// Execute the workflow
@@ -59,7 +71,7 @@ func (s *RefundPaymentTestSuite) TestRefundPayment_RefundProcessingFailure() {
 	refundError := errors.New("refund period expired")
 	
 	// Register mock activities with the test environment
-	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return("", refundError)
+	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return(activities.RefundRecord{}, refundError)
 	
 	// Execute the workflow
 	env.ExecuteWorkflow(workflows.RefundPayment, req)
@@ -83,7 +95,7 @@ func (s *RefundPaymentTestSuite) TestRefundPayment_StatusUpdateFailure() {
 	statusError := errors.New("status update service down")
 	
 	// Register mock activities with the test environment
-	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return("refund-456", nil)
+	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return(fullRefund("refund-456"), nil)
 	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "refunded"}).Return(statusError)
 	
 	// Execute the workflow
@@ -111,7 +123,7 @@ func (s *RefundPaymentTestSuite) TestRefundPayment_MultiplePayments() {
 			}
 			
 			// Register mock activities with the test environment
-			env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return("refund-"+paymentID, nil)
+			env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID}).Return(fullRefund("refund-"+paymentID), nil)
 			env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "refunded"}).Return(nil)
 			
 			// Execute the workflow
@@ -126,4 +138,61 @@ func (s *RefundPaymentTestSuite) TestRefundPayment_MultiplePayments() {
 			s.True(result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func (s *RefundPaymentTestSuite) TestRefundPayment_PartialRefund() {
+	env := s.NewTestWorkflowEnvironment()
+
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+
+	req := RefundRequest{
+		PaymentID: "payment-123",
+		RefundID:  "refund-key",
+		Amount:    common.NewMoney(2500, "USD"),
+		Reason:    "damaged item",
+	}
+	refund := activities.RefundRecord{
+		RefundID:  "refund-456",
+		Amount:    req.Amount,
+		Refunded:  req.Amount,
+		Remaining: common.NewMoney(7499, "USD"),
+	}
+
+	// Register mock activities with the test environment
+	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID, RefundID: req.RefundID, Amount: req.Amount, Reason: req.Reason}).Return(refund, nil)
+	env.OnActivity(mockActivities.UpdatePaymentStatus, mock.Anything, activities.UpdatePaymentStatusRequest{PaymentID: req.PaymentID, Status: "partially_refunded"}).Return(nil)
+
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.RefundPayment, req)
+
+	// Verify workflow completed successfully
+	s.True(env.IsWorkflowCompleted())
+	s.NoError(env.GetWorkflowError())
+	mockActivities.AssertExpectations(s.T())
+}
+
+func (s *RefundPaymentTestSuite) TestRefundPayment_OverRefund() {
+	env := s.NewTestWorkflowEnvironment()
+
+	mockActivities := &MockActivities{}
+	workflows := NewWorkflows(mockActivities)
+
+	req := RefundRequest{
+		PaymentID: "payment-123",
+		Amount:    common.NewMoney(20000, "USD"),
+	}
+	overRefund := temporal.NewNonRetryableApplicationError("refund exceeds remaining balance", activities.ErrTypeOverRefund, nil)
+
+	// Register mock activities with the test environment
+	env.OnActivity(mockActivities.ProcessRefund, mock.Anything, activities.ProcessRefundRequest{PaymentID: req.PaymentID, Amount: req.Amount}).Return(activities.RefundRecord{}, overRefund).Once()
+
+	// Execute the workflow
+	env.ExecuteWorkflow(workflows.RefundPayment, req)
+
+	// Verify the over-refund failed without retries or a status update
+	s.True(env.IsWorkflowCompleted())
+	s.Error(env.GetWorkflowError())
+	s.Contains(env.GetWorkflowError().Error(), "refund exceeds remaining balance")
+	mockActivities.AssertExpectations(s.T())
+}
@@ -0,0 +1,79 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// Capture writes the history of a closed workflow execution to path in the
+// JSON format read by ReplayFile. Run the workflow against a development
+// server (see test-workflows.sh), then capture it into the testdata of the
+// domain it belongs to, named after workflowID when the workflow derives
+// child workflow IDs from its own (see WorkflowID). An empty runID captures
+// the latest run.
+func Capture(ctx context.Context, temporalClient client.Client, workflowID, runID, path string) error {
+	history := &historypb.History{}
+	iter := temporalClient.GetWorkflowHistory(ctx, workflowID, runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed to get history of workflow %s: %w", workflowID, err)
+		}
+		history.Events = append(history.Events, event)
+	}
+
+	if len(history.Events) == 0 {
+		return fmt.Errorf("workflow %s has no history", workflowID)
+	}
+	if !isClosed(history) {
+		return fmt.Errorf("workflow %s is still running", workflowID)
+	}
+
+	return WriteHistory(history, path)
+}
+
+// WriteHistory writes history to path as indented JSON, creating the
+// directory if needed
+func WriteHistory(history *historypb.History, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create history file: %w", err)
+	}
+	defer file.Close()
+
+	marshaler := jsonpb.Marshaler{Indent: "  "}
+	if err := marshaler.Marshal(file, history); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if _, err := file.WriteString("\n"); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return file.Close()
+}
+
+// isClosed reports whether the history ends with the execution closing.
+// Histories of running executions replay too, but only up to the last
+// workflow task, so they check less than they appear to.
+func isClosed(history *historypb.History) bool {
+	switch history.Events[len(history.Events)-1].EventType {
+	case enums.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED,
+		enums.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		return true
+	}
+	return false
+}
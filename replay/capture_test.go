@@ -0,0 +1,67 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// historyClient serves a fixed history through GetWorkflowHistory
+type historyClient struct {
+	client.Client
+	events []*historypb.HistoryEvent
+}
+
+func (c *historyClient) GetWorkflowHistory(ctx context.Context, workflowID string, runID string, isLongPoll bool, filterType enums.HistoryEventFilterType) client.HistoryEventIterator {
+	return &eventIterator{events: c.events}
+}
+
+type eventIterator struct {
+	events []*historypb.HistoryEvent
+}
+
+func (i *eventIterator) HasNext() bool {
+	return len(i.events) > 0
+}
+
+func (i *eventIterator) Next() (*historypb.HistoryEvent, error) {
+	event := i.events[0]
+	i.events = i.events[1:]
+	return event, nil
+}
+
+func TestCapture(t *testing.T) {
+	recorded, err := client.HistoryFromJSON(mustOpen(t, filepath.Join("testdata", "greet_completed.json")), client.HistoryJSONOptions{})
+	require.NoError(t, err)
+
+	t.Run("closed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "greet", "greet_captured.json")
+		err := Capture(context.Background(), &historyClient{events: recorded.Events}, "greet-1", "", path)
+		require.NoError(t, err)
+
+		// The captured history replays like one exported by the CLI
+		require.NoError(t, New(registerGreet(greet)).ReplayFile(path))
+	})
+
+	t.Run("running", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "greet_running.json")
+		err := Capture(context.Background(), &historyClient{events: recorded.Events[:4]}, "greet-1", "", path)
+		assert.ErrorContains(t, err, "still running")
+		assert.NoFileExists(t, path)
+	})
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
@@ -0,0 +1,113 @@
+// Package replay checks workflow code against recorded histories. Replaying
+// a history runs the current workflow code over the events of a past
+// execution and fails when the code would no longer issue the same commands,
+// i.e. when deploying it would break executions that are still open.
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Replayer replays histories against the workflows of a domain
+type Replayer struct {
+	replayer worker.WorkflowReplayer
+}
+
+// New creates a replayer with the workflows registered by register, typically
+// a domain's Register method, so histories are replayed against the workflow
+// types the worker actually serves. Activities are not needed for replay and
+// are ignored.
+func New(register func(worker.Worker)) *Replayer {
+	replayer := worker.NewWorkflowReplayer()
+	register(&registry{replayer: replayer})
+	return &Replayer{replayer: replayer}
+}
+
+// ReplayFile replays the history exported to a JSON file, e.g. by
+// `temporal workflow show --output json` or Capture. Histories do not record
+// their workflow ID, so the execution is replayed under the ID the file is
+// named after, see WorkflowID; workflows deriving child IDs from their own
+// replay only when their history file is named after the original ID.
+func (r *Replayer) ReplayFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to replay %s: %w", path, err)
+	}
+	defer file.Close()
+
+	history, err := client.HistoryFromJSON(file, client.HistoryJSONOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to replay %s: %w", path, err)
+	}
+	err = r.replayer.ReplayWorkflowHistoryWithOptions(nil, history, worker.ReplayWorkflowHistoryOptions{
+		OriginalExecution: workflow.Execution{ID: WorkflowID(path)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay %s: %w", path, err)
+	}
+	return nil
+}
+
+// WorkflowID returns the workflow ID a history file is replayed under: its
+// name without the extension, e.g. "checkout-completed"
+func WorkflowID(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// Run replays every *.json history in dir as a subtest of t. It fails when
+// dir holds no histories, so a mistyped directory does not pass silently.
+func (r *Replayer) Run(t *testing.T, dir string) {
+	t.Helper()
+
+	histories, err := Histories(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) == 0 {
+		t.Fatalf("no histories in %s", dir)
+	}
+
+	for _, history := range histories {
+		t.Run(filepath.Base(history), func(t *testing.T) {
+			if err := r.ReplayFile(history); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Histories returns the paths of the *.json histories in dir
+func Histories(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("failed to read histories: %w", err)
+	}
+	return filepath.Glob(filepath.Join(dir, "*.json"))
+}
+
+// registry lets domains register with a replayer as they do with a worker.
+// Only registration is supported; the embedded worker is never set.
+type registry struct {
+	worker.Worker
+	replayer worker.WorkflowReplayer
+}
+
+func (r *registry) RegisterWorkflow(w interface{}) {
+	r.replayer.RegisterWorkflow(w)
+}
+
+func (r *registry) RegisterWorkflowWithOptions(w interface{}, options workflow.RegisterOptions) {
+	r.replayer.RegisterWorkflowWithOptions(w, options)
+}
+
+func (r *registry) RegisterActivity(a interface{}) {}
+
+func (r *registry) RegisterActivityWithOptions(a interface{}, options activity.RegisterOptions) {}
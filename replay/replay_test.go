@@ -0,0 +1,62 @@
+package replay
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+func greet(ctx workflow.Context, name string) (string, error) {
+	return "hello " + name, nil
+}
+
+func greetWithActivity(ctx workflow.Context, name string) (string, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: 1})
+	var greeting string
+	err := workflow.ExecuteActivity(ctx, "Greet", name).Get(ctx, &greeting)
+	return greeting, err
+}
+
+// registerGreet registers fn as Greet.v1 the way a domain registers with a worker
+func registerGreet(fn any) func(worker.Worker) {
+	return func(w worker.Worker) {
+		w.RegisterWorkflowWithOptions(fn, workflow.RegisterOptions{Name: "Greet.v1"})
+		w.RegisterActivity(func(name string) (string, error) { return name, nil })
+	}
+}
+
+func TestReplayer_ReplayFile(t *testing.T) {
+	history := filepath.Join("testdata", "greet_completed.json")
+
+	t.Run("deterministic", func(t *testing.T) {
+		require.NoError(t, New(registerGreet(greet)).ReplayFile(history))
+	})
+
+	t.Run("nondeterministic", func(t *testing.T) {
+		err := New(registerGreet(greetWithActivity)).ReplayFile(history)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "greet_completed.json")
+		assert.Contains(t, err.Error(), "nondeterministic")
+	})
+}
+
+func TestReplayer_Run(t *testing.T) {
+	New(registerGreet(greet)).Run(t, "testdata")
+}
+
+func TestHistories(t *testing.T) {
+	histories, err := Histories("testdata")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("testdata", "greet_completed.json")}, histories)
+
+	_, err = Histories("missing")
+	assert.Error(t, err)
+}
+
+func TestWorkflowID(t *testing.T) {
+	assert.Equal(t, "checkout-completed", WorkflowID(filepath.Join("testdata", "checkout-completed.json")))
+}
@@ -1,12 +1,14 @@
 #!/bin/bash
 
-# Test API endpoint for triggering order workflow
+# Test API endpoints for triggering order and payment workflows
 
 set -e
 
 API_ENDPOINT="http://localhost:8080/api/workflows/order/process"
+PAYMENT_ENDPOINT="http://localhost:8080/api/workflows/payment/process"
+REFUND_ENDPOINT="http://localhost:8080/api/workflows/payment/refund"
 
-echo "🚀 Testing order processing API endpoint..."
+echo "🚀 Testing workflow API endpoints..."
 
 # Check if API server is running
 if ! curl -s ${API_ENDPOINT} > /dev/null 2>&1; then
@@ -34,7 +36,26 @@ curl -X POST ${API_ENDPOINT} \
   | jq '.'
 
 echo ""
-echo "✅ API endpoint tested!"
+
+# Test Payment Processing
+echo "💳 Testing Payment API..."
+
+echo "  → Processing payment..."
+curl -X POST ${PAYMENT_ENDPOINT} \
+  -H "Content-Type: application/json" \
+  -d '{"paymentId":"api-payment-001","amount":{"minorUnits":2999,"currency":"USD"},"userId":"user-alice"}' \
+  | jq '.'
+
+echo ""
+echo "  → Partially refunding payment..."
+sleep 2
+curl -X POST ${REFUND_ENDPOINT} \
+  -H "Content-Type: application/json" \
+  -d '{"paymentId":"api-payment-001","amount":{"minorUnits":999,"currency":"USD"},"reason":"damaged item","userId":"user-alice"}' \
+  | jq '.'
+
+echo ""
+echo "✅ API endpoints tested!"
 echo ""
 echo "📊 Check workflows with:"
 echo "  temporal workflow list --query \"userId='user-alice'\""
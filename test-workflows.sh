@@ -19,6 +19,7 @@ echo "✅ Worker is running"
 # Register search attribute if not already registered
 echo "🔧 Setting up search attributes..."
 temporal operator search-attribute create --name userId --type Text --namespace claude 2>/dev/null || echo "  → userId search attribute already exists"
+temporal operator search-attribute create --name principal --type Keyword --namespace claude 2>/dev/null || echo "  → principal search attribute already exists"
 
 echo ""
 
@@ -28,7 +29,7 @@ echo "  → Processing regular order..."
 temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessOrder.v1" --input '{"orderId":"order-001"}' --search-attribute 'userId="user-alice"'
 
 echo "  → Processing priority order..."
-temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessOrder.v1" --input '{"orderId":"order-002-priority"}' --search-attribute 'userId="user-bob"'
+temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessOrder.v2" --input '{"orderId":"order-002-priority"}' --search-attribute 'userId="user-bob"'
 
 echo "  → Canceling order..."
 temporal workflow start --task-queue "$TASK_QUEUE" --type "CancelOrder.v1" --input '{"orderId":"order-003-cancel"}' --search-attribute 'userId="user-charlie"'
@@ -38,16 +39,23 @@ echo ""
 # Test Payment Workflows with different scenarios
 echo "💳 Testing Payment Workflows..."
 echo "  → Processing small payment..."
-temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessPayment.v1" --input '{"paymentId":"payment-001","amount":29.99}' --search-attribute 'userId="user-alice"'
+temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessPayment.v1" --input '{"paymentId":"payment-001","amount":{"minorUnits":2999,"currency":"USD"}}' --search-attribute 'userId="user-alice"'
 
 echo "  → Processing large payment..."
-temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessPayment.v1" --input '{"paymentId":"payment-002","amount":299.99}' --search-attribute 'userId="user-bob"'
+temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessPayment.v1" --input '{"paymentId":"payment-002","amount":{"minorUnits":29999,"currency":"USD"}}' --search-attribute 'userId="user-bob"'
 
 echo "  → Processing refund..."
 temporal workflow start --task-queue "$TASK_QUEUE" --type "RefundPayment.v1" --input '{"paymentId":"payment-003"}' --search-attribute 'userId="user-charlie"'
 
 echo "  → Processing international payment..."
-temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessPayment.v1" --input '{"paymentId":"payment-004-intl","amount":149.50}' --search-attribute 'userId="user-diana"'
+temporal workflow start --task-queue "$TASK_QUEUE" --type "ProcessPayment.v1" --input '{"paymentId":"payment-004-intl","amount":{"minorUnits":14950,"currency":"EUR"}}' --search-attribute 'userId="user-diana"'
+
+echo ""
+
+# Test Checkout Workflow
+echo "🛒 Testing Checkout Workflow..."
+echo "  → Checking out order with payment..."
+temporal workflow start --task-queue "$TASK_QUEUE" --type "Checkout.v1" --input '{"orderId":"order-005","paymentId":"payment-005","amount":{"minorUnits":4999,"currency":"USD"}}' --search-attribute 'userId="user-alice"'
 
 echo ""
 echo "✅ All workflows started successfully!"
@@ -101,7 +101,7 @@ func (a *App) runGenerate() error {
 	gen := generator.NewClientGenerator(&cfg.Generator)
 	
 	// Load template
-	if err := gen.SetTemplate(createTemplate()); err != nil {
+	if err := gen.SetTemplate(createTemplate(flags.Target)); err != nil {
 		return fmt.Errorf("failed to set template: %w", err)
 	}
 
@@ -113,15 +113,22 @@ func (a *App) runGenerate() error {
 	// Write output file
 	outputFile := flags.OutputFile
 	if outputFile == "" {
-		outputFile = "client.go"
+		outputFile = flags.Target + ".go"
+		if flags.Target == "child" {
+			// Child stubs live in a package of their own, see ChildTemplate
+			outputFile = filepath.Join("child", "child.go")
+		}
 	}
 
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
 	if err := os.WriteFile(outputFile, generated, 0644); err != nil {
 		return fmt.Errorf("failed to write output file: %w", err)
 	}
 
-	fmt.Printf("Generated %s client code in %s (%d methods)\n", 
-		cfg.Generator.PackageName, outputFile, len(workflows))
+	fmt.Printf("Generated %s %s code in %s (%d methods)\n", 
+		cfg.Generator.PackageName, flags.Target, outputFile, len(workflows))
 
 	return nil
 }
@@ -133,11 +140,12 @@ type GenerateFlags struct {
 	InterfaceFile string
 	ModulePath    string
 	ConfigFile    string
+	Target        string
 }
 
 // parseGenerateFlags parses generate command flags
 func (a *App) parseGenerateFlags() (*GenerateFlags, error) {
-	flags := &GenerateFlags{}
+	flags := &GenerateFlags{Target: "client"}
 	
 	args := os.Args[2:] // Skip program name and command
 	for i := 0; i < len(args); i++ {
@@ -172,6 +180,15 @@ func (a *App) parseGenerateFlags() (*GenerateFlags, error) {
 			}
 			flags.ConfigFile = args[i+1]
 			i++
+		case "-t", "--target":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("missing value for %s", args[i])
+			}
+			if args[i+1] != "client" && args[i+1] != "child" {
+				return nil, fmt.Errorf("unknown target: %s", args[i+1])
+			}
+			flags.Target = args[i+1]
+			i++
 		default:
 			return nil, fmt.Errorf("unknown flag: %s", args[i])
 		}
@@ -194,7 +211,8 @@ COMMANDS:
 
 GENERATE OPTIONS:
     -d, --domain STRING         Domain name (required)
-    -o, --output STRING         Output file path (default: client.go)
+    -t, --target STRING         What to generate: client or child (default: client)
+    -o, --output STRING         Output file path (default: client.go or child/child.go)
     -i, --interface STRING      Interface file path (default: interfaces.go)
     -m, --module STRING         Module path (default: simple-temporal-workflow)
     -c, --config STRING         Configuration file path
@@ -203,6 +221,7 @@ EXAMPLES:
     clientgen generate -d order -o client.go
     clientgen generate -d payment -i payment_interfaces.go -o payment_client.go
     clientgen generate -d shipping -c config.yaml
+    clientgen generate -d payment -t child
 
 For more information, visit: https://github.com/your-org/temporal-client-generator`)
 
@@ -215,10 +234,15 @@ func (a *App) showVersion() error {
 	return nil
 }
 
-// createTemplate creates and loads the template
-func createTemplate() generator.Template {
+// createTemplate creates and loads the template for target
+func createTemplate(target string) generator.Template {
+	source := generator.ClientTemplate
+	if target == "child" {
+		source = generator.ChildTemplate
+	}
+
 	goTemplate := generator.NewGoTemplate()
-	err := goTemplate.Load(generator.ClientTemplate)
+	err := goTemplate.Load(source)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to load template: %v", err))
 	}
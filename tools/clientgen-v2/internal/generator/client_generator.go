@@ -169,6 +169,7 @@ func getTemplateFunctions() template.FuncMap {
 		"extractIDField": extractIDField,
 		"extractEntity": extractEntity,
 		"toDescription": toDescription,
+		"toWorkflowType": toWorkflowType,
 		"toLower":       strings.ToLower,
 		"ne":           func(a, b string) bool { return a != b },
 	}
@@ -193,6 +194,19 @@ func toKebabCase(s string) string {
 	return result.String()
 }
 
+// toWorkflowType returns the registered type of a workflow method, e.g.
+// "ProcessOrder.v2" for ProcessOrderV2 and "CancelOrder.v1" for CancelOrder
+func toWorkflowType(name string) string {
+	i := len(name)
+	for i > 0 && name[i-1] >= '0' && name[i-1] <= '9' {
+		i--
+	}
+	if i < len(name) && i > 1 && name[i-1] == 'V' {
+		return name[:i-1] + ".v" + name[i:]
+	}
+	return name + ".v1"
+}
+
 func extractIDField(inputType string) string {
 	entityName := strings.TrimSuffix(inputType, "Request")
 	if entityName != "" && entityName != inputType {
@@ -48,4 +48,33 @@ func (c *{{$.PackageName}}Client) {{.Name}}(ctx context.Context, req {{.Name}}Re
 		SuccessMessage:   fmt.Sprintf("{{.Name | toDescription}} workflow started for {{.InputType | extractEntity}} %s", req.{{.InputType | extractIDField}}),
 	})
 }
-{{end}}`
\ No newline at end of file
+{{end}}`
+const ChildTemplate = `// Code generated by clientgen-v2. DO NOT EDIT.
+
+// Package child starts {{.PackageName}} workflows as children of another
+// workflow. Children get a workflow ID derived from their parent's and are
+// cancelled with it unless overridden per call. Unlike the {{.PackageName}}
+// package it does not register the domain on import, so workflows of other
+// domains can depend on it.
+package child
+
+import (
+	"{{.ModulePath}}/common"
+	"{{.ModulePath}}/{{.PackageName}}/workflows"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Domain is the name of the domain the workflows belong to, e.g. to look up
+// the task queue it is polled on
+const Domain = "{{.PackageName}}"
+
+// Workflow types of the {{.PackageName}} domain
+const ({{range .WorkflowMethods}}
+	{{.Name}}Workflow = "{{.Name | toWorkflowType}}"{{end}}
+)
+{{range .WorkflowMethods}}
+// {{.Name}} starts a {{.Name | toWorkflowType}} child workflow
+func {{.Name}}(ctx workflow.Context, req workflows.{{.InputType}}, opts ...common.ChildOption) *common.ChildRun[{{.OutputType}}] {
+	return common.ExecuteChild[{{.OutputType}}](ctx, {{.Name}}Workflow, req, opts...)
+}
+{{end}}`
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/interceptor"
+)
+
+// HeaderKey is the Temporal header carrying the trace context. It matches
+// the key of the SDK's OpenTelemetry integration so traces connect across
+// services using either.
+const HeaderKey = "_tracer-data"
+
+type spanContextKey struct{}
+
+// NewInterceptor returns the Temporal tracing interceptor recording spans
+// for workflow starts, signals and queries on the client, and for workflow,
+// activity and child workflow executions on the worker. Set on the client,
+// it applies to the workers created from it too.
+func NewInterceptor() interceptor.Interceptor {
+	return interceptor.NewTracingInterceptor(&temporalTracer{
+		tracer: otel.Tracer(InstrumentationName),
+	})
+}
+
+// temporalTracer adapts OpenTelemetry to the SDK's tracing interceptor
+type temporalTracer struct {
+	interceptor.BaseTracer
+	tracer trace.Tracer
+}
+
+// temporalSpan is a span started by the interceptor
+type temporalSpan struct {
+	trace.Span
+}
+
+// temporalSpanRef is a span read from a Temporal header
+type temporalSpanRef struct {
+	trace.SpanContext
+}
+
+func (t *temporalTracer) Options() interceptor.TracerOptions {
+	return interceptor.TracerOptions{
+		SpanContextKey: spanContextKey{},
+		HeaderKey:      HeaderKey,
+	}
+}
+
+func (t *temporalTracer) UnmarshalSpan(data map[string]string) (interceptor.TracerSpanRef, error) {
+	spanContext := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(data)))
+	if !spanContext.IsValid() {
+		return nil, fmt.Errorf("no trace context in header")
+	}
+	return &temporalSpanRef{SpanContext: spanContext}, nil
+}
+
+func (t *temporalTracer) MarshalSpan(span interceptor.TracerSpan) (map[string]string, error) {
+	data := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpan(context.Background(), span.(*temporalSpan).Span), data)
+	return data, nil
+}
+
+func (t *temporalTracer) SpanFromContext(ctx context.Context) interceptor.TracerSpan {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil
+	}
+	return &temporalSpan{Span: span}
+}
+
+func (t *temporalTracer) ContextWithSpan(ctx context.Context, span interceptor.TracerSpan) context.Context {
+	return trace.ContextWithSpan(ctx, span.(*temporalSpan).Span)
+}
+
+func (t *temporalTracer) StartSpan(options *interceptor.TracerStartSpanOptions) (interceptor.TracerSpan, error) {
+	parent := context.Background()
+	switch ref := options.Parent.(type) {
+	case *temporalSpan:
+		parent = trace.ContextWithSpan(parent, ref.Span)
+	case *temporalSpanRef:
+		parent = trace.ContextWithRemoteSpanContext(parent, ref.SpanContext)
+	}
+
+	attributes := make([]attribute.KeyValue, 0, len(options.Tags))
+	for key, value := range options.Tags {
+		attributes = append(attributes, attribute.String(key, value))
+	}
+
+	_, span := t.tracer.Start(parent, t.SpanName(options),
+		trace.WithTimestamp(options.Time),
+		trace.WithAttributes(attributes...),
+		trace.WithSpanKind(spanKind(options.Operation)),
+	)
+	return &temporalSpan{Span: span}, nil
+}
+
+func (s *temporalSpan) Finish(options *interceptor.TracerFinishSpanOptions) {
+	if options.Error != nil {
+		s.RecordError(options.Error)
+		s.SetStatus(codes.Error, options.Error.Error())
+	}
+	s.End()
+}
+
+// spanKind maps interceptor operations onto span kinds: starting something
+// is a client call, running or handling it the server side
+func spanKind(operation string) trace.SpanKind {
+	switch operation {
+	case "StartWorkflow", "SignalWithStartWorkflow", "SignalWorkflow", "QueryWorkflow", "CreateSchedule",
+		"StartActivity", "StartChildWorkflow", "SignalExternalWorkflow", "SignalChildWorkflow":
+		return trace.SpanKindClient
+	case "RunWorkflow", "RunActivity", "HandleSignal", "HandleQuery":
+		return trace.SpanKindServer
+	}
+	return trace.SpanKindInternal
+}
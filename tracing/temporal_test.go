@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+func reserve(ctx context.Context, orderID string) (string, error) {
+	return "reservation-" + orderID, nil
+}
+
+func ship(ctx context.Context, orderID string) (string, error) {
+	return "", temporal.NewNonRetryableApplicationError("carrier unavailable", "ShippingError", nil)
+}
+
+func fulfil(ctx workflow.Context, orderID string) (string, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	var reservationID string
+	if err := workflow.ExecuteActivity(ctx, reserve, orderID).Get(ctx, &reservationID); err != nil {
+		return "", err
+	}
+	return reservationID, workflow.ExecuteActivity(ctx, ship, orderID).Get(ctx, nil)
+}
+
+// recordSpans installs a tracer provider recording every span for the
+// duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestInterceptor_WorkflowAndActivitySpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(worker.Options{
+		Interceptors: []interceptor.WorkerInterceptor{NewInterceptor()},
+	})
+	env.RegisterWorkflow(fulfil)
+	env.RegisterActivity(reserve)
+	env.RegisterActivity(ship)
+
+	env.ExecuteWorkflow(fulfil, "order-1")
+	require.True(t, env.IsWorkflowCompleted())
+	require.Error(t, env.GetWorkflowError())
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "RunWorkflow:fulfil")
+	require.Contains(t, spans, "StartActivity:reserve")
+	require.Contains(t, spans, "RunActivity:reserve")
+	require.Contains(t, spans, "RunActivity:ship")
+
+	// Activities run in the trace of the workflow that scheduled them
+	workflowSpan := spans["RunWorkflow:fulfil"]
+	assert.Equal(t, trace.SpanKindServer, workflowSpan.SpanKind())
+	assert.Equal(t, workflowSpan.SpanContext().SpanID(), spans["StartActivity:reserve"].Parent().SpanID())
+	assert.Equal(t, spans["StartActivity:reserve"].SpanContext().SpanID(), spans["RunActivity:reserve"].Parent().SpanID())
+	assert.Equal(t, workflowSpan.SpanContext().TraceID(), spans["RunActivity:ship"].SpanContext().TraceID())
+
+	// Failures are recorded on the spans they happened in
+	assert.Equal(t, codes.Error, spans["RunActivity:ship"].Status().Code)
+	assert.Equal(t, codes.Unset, spans["RunActivity:reserve"].Status().Code)
+}
+
+func TestTracer_MarshalSpan(t *testing.T) {
+	recordSpans(t)
+	tracer := &temporalTracer{tracer: otel.Tracer(InstrumentationName)}
+
+	span, err := tracer.StartSpan(&interceptor.TracerStartSpanOptions{Operation: "StartWorkflow", Name: "ProcessOrder.v2", Time: time.Now()})
+	require.NoError(t, err)
+	defer span.Finish(&interceptor.TracerFinishSpanOptions{})
+
+	// The span travels in the Temporal header as W3C trace context
+	header, err := tracer.MarshalSpan(span)
+	require.NoError(t, err)
+	assert.Contains(t, header, "traceparent")
+
+	ref, err := tracer.UnmarshalSpan(header)
+	require.NoError(t, err)
+	assert.Equal(t, span.(*temporalSpan).SpanContext().TraceID(), ref.(*temporalSpanRef).TraceID())
+
+	_, err = tracer.UnmarshalSpan(map[string]string{})
+	assert.Error(t, err)
+}
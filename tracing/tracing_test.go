@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), ExporterNone)
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+
+		// Trace context is propagated even when spans are not exported
+		assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		shutdown, err := Setup(context.Background(), ExporterStdout)
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := Setup(context.Background(), "zipkin")
+		assert.ErrorContains(t, err, `unknown trace exporter "zipkin"`)
+	})
+}
@@ -2,20 +2,23 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"simple-temporal-workflow/api"
-	"simple-temporal-workflow/order"
-	"simple-temporal-workflow/order/activities"
-	"simple-temporal-workflow/payment"
-	paymentactivities "simple-temporal-workflow/payment/activities"
+	"simple-temporal-workflow/domain"
+	"simple-temporal-workflow/logging"
+	"simple-temporal-workflow/metrics"
+	"simple-temporal-workflow/tracing"
 	myworker "simple-temporal-workflow/worker"
 	"syscall"
 	"time"
 
-	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/client"
 )
 
 const (
@@ -26,83 +29,229 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	orderActivities := activities.NewActivities()
-	orderWorkflows := order.NewWorkflows(orderActivities)
-	orderOrchestrator := order.NewOrchestrator(orderWorkflows, orderActivities)
-
-	paymentActivities := paymentactivities.NewActivities()
-	paymentWorkflows := payment.NewWorkflows(paymentActivities)
-	paymentOrchestrator := payment.NewOrchestrator(paymentWorkflows, paymentActivities)
-
-	config := myworker.DefaultConfig()
-	if taskQueue := os.Getenv("TEMPORAL_TASK_QUEUE"); taskQueue != "" {
-		config.TaskQueue = taskQueue
+	// Read the configuration file, environment and flags
+	config, err := myworker.Load(os.Args[0], os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fatal("Failed to load configuration", err)
 	}
-	if hostPort := os.Getenv("TEMPORAL_HOST_PORT"); hostPort != "" {
-		config.HostPort = hostPort
+	domains := make([]string, 0, len(domain.All()))
+	for _, d := range domain.All() {
+		domains = append(domains, d.Name())
+	}
+	if err := config.CheckDomains(domains); err != nil {
+		fatal("Failed to load configuration", err)
 	}
 
-	embeddedWorker, err := myworker.NewEmbeddedWorker(config, func(w worker.Worker) {
-		orderOrchestrator.RegisterWithWorker(w)
-		paymentOrchestrator.RegisterWithWorker(w)
-	})
+	// Log everything, the Temporal client and API errors included, through
+	// the configured logger
+	logger, err := logging.New(config.Logging, os.Stderr)
 	if err != nil {
-		log.Fatalf("Failed to create embedded worker: %v", err)
+		fatal("Failed to configure logging", err)
 	}
+	slog.SetDefault(logger)
+	slog.Info("Loaded configuration", "Config", config)
 
-	if err := embeddedWorker.Start(ctx); err != nil {
-		log.Fatalf("Failed to start worker: %v", err)
+	// Export traces as configured, none by default
+	shutdownTracing, err := tracing.Setup(ctx, config.TracesExporter)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
 	}
 
-	// Configure orchestrators with client
-	orderOrchestrator.SetClient(embeddedWorker.GetClient(), config.TaskQueue)
+	// The worker and the API share the Temporal client
+	temporalClient, err := myworker.Dial(config, logger)
+	if err != nil {
+		fatal("Failed to connect to Temporal", err)
+	}
 
-	// Create domain clients using the same temporal client
-	temporalClient := embeddedWorker.GetClient()
-	orderClient := order.NewClient(temporalClient, config.TaskQueue)
+	// Every mode serves the health endpoints, ready once the parts it runs
+	// and Temporal are
+	healthServer := myworker.NewHealthServer(config.HealthAddr, metrics.Default, logger)
 
-	// Start API server for workflow triggers
-	apiServer := api.NewServer(orderClient)
-	mux := http.NewServeMux()
-	apiServer.RegisterRoutes(mux)
+	// Register every domain's workflows and activities on its task queue
+	var embeddedWorker *myworker.EmbeddedWorker
+	var workerErrs <-chan error
+	if config.RunsWorker() {
+		embeddedWorker = myworker.NewEmbeddedWorker(config, temporalClient, logger, domain.Registration(config.TaskQueueFor))
+		healthServer.AddLivenessCheck(myworker.CheckWorker, embeddedWorker.CheckAlive)
+		healthServer.AddHealthCheck(myworker.CheckWorker, embeddedWorker.CheckWorker)
+		workerErrs = embeddedWorker.Errors()
+	}
+	apiListener := &myworker.ListenerCheck{}
+	if config.RunsAPI() {
+		healthServer.AddHealthCheck(myworker.CheckAPI, apiListener.Check)
+	}
+	healthServer.AddTemporalChecks(temporalClient, config.Namespace)
+	if config.RunsWorker() {
+		// Report the health of the dependencies of the domains' activities
+		for _, d := range domain.All() {
+			if checker, ok := d.(domain.HealthChecker); ok {
+				healthServer.AddHealthCheck(d.Name(), checker.CheckHealth)
+			}
+		}
+	}
+	healthServer.Start()
 
-	apiHttpServer := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+	if embeddedWorker != nil {
+		if err := embeddedWorker.Start(ctx); err != nil {
+			fatal("Failed to start worker", err)
+		}
 	}
 
-	go func() {
-		log.Printf("Starting API server on :8080")
-		if err := apiHttpServer.ListenAndServe(); err != http.ErrServerClosed {
-			log.Printf("API server error: %v", err)
+	// Start API server for workflow triggers
+	var apiHttpServer *http.Server
+	apiErrs := make(chan error, 1)
+	if config.RunsAPI() {
+		apiHttpServer, err = startAPIServer(config, temporalClient, apiListener, apiErrs)
+		if err != nil {
+			fatal("Failed to start API server", err)
 		}
-	}()
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	log.Printf("Microservice started successfully")
-	log.Printf("- Task Queue: %s", config.TaskQueue)
-	log.Printf("- Temporal Host: %s", config.HostPort)
-	log.Printf("- Health endpoint: http://localhost:9090/health")
-	log.Printf("- Ready endpoint: http://localhost:9090/ready")
-	log.Printf("- Metrics endpoint: http://localhost:9090/metrics")
-	log.Printf("- API endpoints: http://localhost:8080/api/workflows/*")
+	attrs := []any{
+		"Mode", config.Mode,
+		"Domains", domains,
+		"TemporalHost", config.HostPort,
+		"HealthEndpoints", config.HealthAddr + " /health /ready /metrics",
+	}
+	if config.RunsWorker() {
+		attrs = append(attrs, "TaskQueues", config.AllTaskQueues())
+	}
+	if config.RunsAPI() {
+		attrs = append(attrs, "APIEndpoints", config.API.Addr+" /api/workflows/*")
+	}
+	slog.Info("Microservice started successfully", attrs...)
 
-	<-sigChan
-	log.Printf("Received shutdown signal, starting graceful shutdown...")
+	// Run until a shutdown signal, or until the worker or the API server
+	// fails, in which case exit non-zero after shutting down the rest
+	failed := false
+	select {
+	case sig := <-sigChan:
+		slog.Info("Received shutdown signal, starting graceful shutdown", "Signal", sig.String())
+	case err := <-workerErrs:
+		slog.Error("Worker failed, shutting down", "Error", err)
+		failed = true
+	case err := <-apiErrs:
+		slog.Error("API server failed, shutting down", "Error", err)
+		failed = true
+	}
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
 
 	// Stop API server
-	if err := apiHttpServer.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Error during API server shutdown: %v", err)
+	if apiHttpServer != nil {
+		if err := apiHttpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Error during API server shutdown", "Error", err)
+			failed = true
+		}
+	}
+
+	if embeddedWorker != nil {
+		if err := embeddedWorker.Stop(shutdownCtx); err != nil {
+			slog.Error("Error during worker shutdown", "Error", err)
+			failed = true
+		}
+	}
+
+	if err := healthServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error during health server shutdown", "Error", err)
+		failed = true
+	}
+
+	// Close Temporal client, which also fails the polls of a worker that
+	// did not stop in time
+	temporalClient.Close()
+
+	// Flush the spans of the last requests
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Error during tracing shutdown", "Error", err)
+		failed = true
+	}
+
+	slog.Info("Microservice shut down completed")
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "Error", err)
+	os.Exit(1)
+}
+
+// startAPIServer serves the routes of every domain, whose clients start
+// workflows through temporalClient. It returns once the server listens,
+// reporting it to listener, and reports the error the server stops on to
+// errs.
+func startAPIServer(config *myworker.Config, temporalClient client.Client, listener *myworker.ListenerCheck, errs chan<- error) (*http.Server, error) {
+	// Create every domain client using the same temporal client
+	domain.NewClients(temporalClient, config.TaskQueueFor)
+
+	apiServer := api.NewServer(domain.RouteProviders()...)
+	authenticator, err := newAuthenticator(config.API)
+	if err != nil {
+		return nil, err
+	}
+	if authenticator != nil {
+		apiServer.SetAuthenticator(authenticator)
+	}
+	apiServer.SetMetrics(metrics.Default)
+	mux := http.NewServeMux()
+	if err := apiServer.RegisterRoutes(mux); err != nil {
+		return nil, err
+	}
+
+	apiHttpServer := &http.Server{
+		Addr:    config.API.Addr,
+		Handler: mux,
+	}
+
+	ln, err := net.Listen("tcp", apiHttpServer.Addr)
+	if err != nil {
+		return nil, err
+	}
+	listener.Listening()
+
+	go func() {
+		slog.Info("Starting API server", "Addr", apiHttpServer.Addr)
+		err := apiHttpServer.Serve(ln)
+		listener.Stopped(err)
+		if err != http.ErrServerClosed {
+			errs <- err
+		}
+	}()
+	return apiHttpServer, nil
+}
+
+// newAuthenticator builds the API authenticator chain from the configured
+// API keys and JWT secret. It returns nil when neither is set, leaving the
+// API open.
+func newAuthenticator(config myworker.APIConfig) (api.Authenticator, error) {
+	var authenticators []api.Authenticator
+
+	if config.APIKeys != "" {
+		apiKeys, err := api.ParseAPIKeys(config.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, apiKeys)
+	}
+
+	if config.JWTSecret != "" {
+		authenticators = append(authenticators, api.NewJWTAuthenticator([]byte(config.JWTSecret), config.JWTIssuer, config.JWTAudience))
 	}
 
-	if err := embeddedWorker.Stop(shutdownCtx); err != nil {
-		log.Printf("Error during worker shutdown: %v", err)
+	if len(authenticators) == 0 {
+		slog.Warn("API authentication disabled, set API_KEYS or JWT_HMAC_SECRET to enable it")
+		return nil, nil
 	}
 
-	log.Printf("Microservice shut down completed")
-}
\ No newline at end of file
+	return api.ChainAuthenticators(authenticators...), nil
+}
@@ -1,49 +1,404 @@
 package worker
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
+	"simple-temporal-workflow/logging"
+	"simple-temporal-workflow/tracing"
 	"go.temporal.io/sdk/worker"
 )
 
+// redacted replaces secrets in the effective configuration
+const redacted = "[REDACTED]"
+
+// Run modes, selecting the parts of the service a process runs
+const (
+	ModeAll    = "all"    // The worker and the API
+	ModeWorker = "worker" // The worker, without the API
+	ModeAPI    = "api"    // The API, starting workflows for workers run elsewhere
+)
+
+// Config is the configuration of the service: the Temporal connection, the
+// worker, the health and API servers, and logging and tracing. See Load for
+// how it is read from a file, the environment and flags.
 type Config struct {
+	// Mode is one of the Mode* names. Every mode serves the health endpoints.
+	Mode string `yaml:"mode"`
+
 	// Temporal connection
-	HostPort  string
-	Namespace string
-	TaskQueue string
+	HostPort    string            `yaml:"hostPort"`
+	Namespace   string            `yaml:"namespace"`
+	TLS         TLSConfig         `yaml:"tls"`
+	Credentials CredentialsConfig `yaml:"credentials"`
+
+	// TaskQueue is polled for every domain without an entry in TaskQueues,
+	// which moves domains to task queues of their own
+	TaskQueue  string            `yaml:"taskQueue"`
+	TaskQueues map[string]string `yaml:"taskQueues"`
 
-	// Worker configuration for low volume
-	MaxConcurrentWorkflows int
-	MaxConcurrentActivities int
+	// Execution slots: the tasks each worker runs at once
+	MaxConcurrentWorkflowTasks   int `yaml:"maxConcurrentWorkflowTasks"`
+	MaxConcurrentActivities      int `yaml:"maxConcurrentActivities"`
+	MaxConcurrentLocalActivities int `yaml:"maxConcurrentLocalActivities"`
+
+	// Pollers: the long polls each worker fetches tasks with, at most as many
+	// as there are slots to run the tasks in
+	WorkflowTaskPollers int `yaml:"workflowTaskPollers"`
+	ActivityTaskPollers int `yaml:"activityTaskPollers"`
+
+	// Activity rate limits in activities per second, per worker and across
+	// every worker of the task queue. Zero leaves them unlimited.
+	WorkerActivitiesPerSecond    float64 `yaml:"workerActivitiesPerSecond"`
+	TaskQueueActivitiesPerSecond float64 `yaml:"taskQueueActivitiesPerSecond"`
+
+	// StickyCacheSize is the number of workflows kept in memory between
+	// their tasks, shared by every worker of the process
+	StickyCacheSize int `yaml:"stickyCacheSize"`
+
+	Sessions   SessionConfig    `yaml:"sessions"`
+	Versioning VersioningConfig `yaml:"versioning"`
 
 	// Resource limits
-	WorkerStopTimeout time.Duration
+	WorkerStopTimeout time.Duration `yaml:"workerStopTimeout"`
+
+	// Listen address of the health, readiness and metrics endpoints
+	HealthAddr string `yaml:"healthAddr"`
+
+	// Workflow-trigger API
+	API APIConfig `yaml:"api"`
+
+	// Level and format of the logs of the worker, workflows and activities
+	Logging logging.Config `yaml:"logging"`
+
+	// TracesExporter is one of the tracing.Exporter* names
+	TracesExporter string `yaml:"tracesExporter"`
+}
+
+// TLSConfig secures the connection to Temporal. It is enabled by setting a
+// CA file, a client certificate for mTLS, or InsecureSkipVerify, and always
+// used with an API key. The client certificate is reloaded when its files
+// change.
+type TLSConfig struct {
+	CAFile             string `yaml:"caFile"`             // CA bundle verifying the server, the system pool when empty
+	CertFile           string `yaml:"certFile"`           // Client certificate for mTLS
+	KeyFile            string `yaml:"keyFile"`            // Key of the client certificate
+	ServerName         string `yaml:"serverName"`         // Overrides the name verified on the server certificate
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"` // Accepts any server certificate, for development only
+}
+
+// CredentialsConfig authenticates requests to Temporal with an API key,
+// sent as a bearer token, and any other headers
+type CredentialsConfig struct {
+	APIKey  string            `yaml:"apiKey"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// SessionConfig enables sessions, which pin a sequence of activities to
+// one worker. No domain uses them yet.
+type SessionConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	MaxConcurrent int  `yaml:"maxConcurrent"` // Sessions each worker runs at once
+}
+
+// VersioningConfig opts the worker into worker versioning, where it only
+// gets the tasks of workflows compatible with its build ID. Sessions cannot
+// be enabled at the same time.
+type VersioningConfig struct {
+	BuildID    string `yaml:"buildId"`
+	UseBuildID bool   `yaml:"useBuildId"`
+}
+
+// APIConfig configures the workflow-trigger API server and its
+// authentication, which is disabled when neither API keys nor a JWT secret
+// are set
+type APIConfig struct {
+	Addr        string `yaml:"addr"`
+	APIKeys     string `yaml:"apiKeys"`   // In the api.ParseAPIKeys format
+	JWTSecret   string `yaml:"jwtSecret"` // HMAC secret of HS256 tokens
+	JWTIssuer   string `yaml:"jwtIssuer"`
+	JWTAudience string `yaml:"jwtAudience"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
+		Mode: ModeAll,
+
 		HostPort:  "localhost:7233",
 		Namespace: "claude",
 		TaskQueue: "microservice-task-queue",
 
 		// Low volume settings - 10 concurrent as specified
-		MaxConcurrentWorkflows:  10,
-		MaxConcurrentActivities: 10,
+		MaxConcurrentWorkflowTasks:   10,
+		MaxConcurrentActivities:      10,
+		MaxConcurrentLocalActivities: 10,
+		WorkflowTaskPollers:          2,
+		ActivityTaskPollers:          2,
+		StickyCacheSize:              1000,
+
+		Sessions: SessionConfig{MaxConcurrent: 100},
 
 		WorkerStopTimeout: 30 * time.Second,
+
+		HealthAddr: ":9090",
+		API:        APIConfig{Addr: ":8080"},
+
+		Logging:        logging.DefaultConfig(),
+		TracesExporter: tracing.ExporterNone,
+	}
+}
+
+// RunsWorker reports whether the mode runs the worker
+func (c *Config) RunsWorker() bool {
+	return c.Mode == ModeAll || c.Mode == ModeWorker
+}
+
+// RunsAPI reports whether the mode runs the API
+func (c *Config) RunsAPI() bool {
+	return c.Mode == ModeAll || c.Mode == ModeAPI
+}
+
+// TaskQueueFor returns the task queue of a domain
+func (c *Config) TaskQueueFor(domain string) string {
+	if taskQueue := c.TaskQueues[domain]; taskQueue != "" {
+		return taskQueue
+	}
+	return c.TaskQueue
+}
+
+// AllTaskQueues returns the default task queue followed by the other
+// domain task queues in order, each once
+func (c *Config) AllTaskQueues() []string {
+	taskQueues := []string{c.TaskQueue}
+	seen := map[string]bool{c.TaskQueue: true}
+
+	domains := make([]string, 0, len(c.TaskQueues))
+	for domain := range c.TaskQueues {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		if taskQueue := c.TaskQueueFor(domain); !seen[taskQueue] {
+			seen[taskQueue] = true
+			taskQueues = append(taskQueues, taskQueue)
+		}
+	}
+	return taskQueues
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Mode == ModeAll || c.Mode == ModeWorker || c.Mode == ModeAPI, "mode must be %s, %s or %s, got %q", ModeAll, ModeWorker, ModeAPI, c.Mode)
+	check(c.HostPort != "", "hostPort is required")
+	check(c.Namespace != "", "namespace is required")
+	check(c.TaskQueue != "", "taskQueue is required")
+	for domain, taskQueue := range c.TaskQueues {
+		check(domain != "" && taskQueue != "", "taskQueues entry %q=%q needs a domain and a task queue", domain, taskQueue)
+	}
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "tls.certFile and tls.keyFile must be set together")
+	for name := range c.Credentials.Headers {
+		check(name != "", "credentials.headers entries need a name")
+		check(c.Credentials.APIKey == "" || !strings.EqualFold(name, "authorization"), "credentials.apiKey and an authorization header cannot both be set")
+	}
+
+	// The SDK rejects a single workflow task slot or poller, which would
+	// only ever poll the sticky queue
+	check(c.MaxConcurrentWorkflowTasks >= 2, "maxConcurrentWorkflowTasks must be at least 2, got %d", c.MaxConcurrentWorkflowTasks)
+	check(c.MaxConcurrentActivities > 0, "maxConcurrentActivities must be positive, got %d", c.MaxConcurrentActivities)
+	check(c.MaxConcurrentLocalActivities > 0, "maxConcurrentLocalActivities must be positive, got %d", c.MaxConcurrentLocalActivities)
+	check(c.WorkflowTaskPollers >= 2, "workflowTaskPollers must be at least 2, got %d", c.WorkflowTaskPollers)
+	check(c.ActivityTaskPollers > 0, "activityTaskPollers must be positive, got %d", c.ActivityTaskPollers)
+	check(c.WorkflowTaskPollers <= c.MaxConcurrentWorkflowTasks, "workflowTaskPollers (%d) must not exceed maxConcurrentWorkflowTasks (%d)", c.WorkflowTaskPollers, c.MaxConcurrentWorkflowTasks)
+	check(c.ActivityTaskPollers <= c.MaxConcurrentActivities, "activityTaskPollers (%d) must not exceed maxConcurrentActivities (%d)", c.ActivityTaskPollers, c.MaxConcurrentActivities)
+	check(c.WorkerActivitiesPerSecond >= 0, "workerActivitiesPerSecond must not be negative, got %g", c.WorkerActivitiesPerSecond)
+	check(c.TaskQueueActivitiesPerSecond >= 0, "taskQueueActivitiesPerSecond must not be negative, got %g", c.TaskQueueActivitiesPerSecond)
+	check(c.StickyCacheSize > 0, "stickyCacheSize must be positive, got %d", c.StickyCacheSize)
+	check(!c.Sessions.Enabled || c.Sessions.MaxConcurrent > 0, "sessions.maxConcurrent must be positive, got %d", c.Sessions.MaxConcurrent)
+	check(!c.Versioning.UseBuildID || c.Versioning.BuildID != "", "versioning.buildId is required with versioning.useBuildId")
+	check(!(c.Versioning.UseBuildID && c.Sessions.Enabled), "sessions cannot be enabled with versioning.useBuildId")
+	check(c.WorkerStopTimeout >= 0, "workerStopTimeout must not be negative, got %s", c.WorkerStopTimeout)
+
+	check(c.HealthAddr != "", "healthAddr is required")
+	if c.RunsAPI() {
+		check(c.API.Addr != "", "api.addr is required")
+		check(c.HealthAddr != c.API.Addr, "healthAddr and api.addr must differ, both are %q", c.HealthAddr)
+	}
+
+	if err := c.Logging.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("logging: %w", err))
+	}
+	if err := tracing.ValidateExporter(c.TracesExporter); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// CheckDomains rejects taskQueues entries naming none of the domains, which
+// would otherwise be ignored and leave a misspelled domain on the default
+// task queue
+func (c *Config) CheckDomains(domains []string) error {
+	known := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		known[domain] = true
+	}
+
+	var unknown []string
+	for domain := range c.TaskQueues {
+		if !known[domain] {
+			unknown = append(unknown, domain)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("invalid configuration: taskQueues names unknown domains %s, known domains are %s",
+		strings.Join(unknown, ", "), strings.Join(domains, ", "))
+}
+
+// LogValue logs the effective configuration with its secrets redacted
+func (c *Config) LogValue() slog.Value {
+	secret := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return redacted
+	}
+
+	return slog.GroupValue(
+		slog.String("Mode", c.Mode),
+		slog.String("HostPort", c.HostPort),
+		slog.String("Namespace", c.Namespace),
+		slog.Group("TLS",
+			slog.String("CAFile", c.TLS.CAFile),
+			slog.String("CertFile", c.TLS.CertFile),
+			slog.String("KeyFile", c.TLS.KeyFile),
+			slog.String("ServerName", c.TLS.ServerName),
+			slog.Bool("InsecureSkipVerify", c.TLS.InsecureSkipVerify),
+		),
+		slog.Group("Credentials",
+			slog.String("APIKey", secret(c.Credentials.APIKey)),
+			slog.Any("Headers", redactValues(c.Credentials.Headers)),
+		),
+		slog.String("TaskQueue", c.TaskQueue),
+		slog.Any("TaskQueues", c.TaskQueues),
+		slog.Int("MaxConcurrentWorkflowTasks", c.MaxConcurrentWorkflowTasks),
+		slog.Int("MaxConcurrentActivities", c.MaxConcurrentActivities),
+		slog.Int("MaxConcurrentLocalActivities", c.MaxConcurrentLocalActivities),
+		slog.Int("WorkflowTaskPollers", c.WorkflowTaskPollers),
+		slog.Int("ActivityTaskPollers", c.ActivityTaskPollers),
+		slog.Float64("WorkerActivitiesPerSecond", c.WorkerActivitiesPerSecond),
+		slog.Float64("TaskQueueActivitiesPerSecond", c.TaskQueueActivitiesPerSecond),
+		slog.Int("StickyCacheSize", c.StickyCacheSize),
+		slog.Group("Sessions",
+			slog.Bool("Enabled", c.Sessions.Enabled),
+			slog.Int("MaxConcurrent", c.Sessions.MaxConcurrent),
+		),
+		slog.Group("Versioning",
+			slog.String("BuildID", c.Versioning.BuildID),
+			slog.Bool("UseBuildID", c.Versioning.UseBuildID),
+		),
+		slog.Duration("WorkerStopTimeout", c.WorkerStopTimeout),
+		slog.String("HealthAddr", c.HealthAddr),
+		slog.Group("API",
+			slog.String("Addr", c.API.Addr),
+			slog.String("APIKeys", secret(c.API.APIKeys)),
+			slog.String("JWTSecret", secret(c.API.JWTSecret)),
+			slog.String("JWTIssuer", c.API.JWTIssuer),
+			slog.String("JWTAudience", c.API.JWTAudience),
+		),
+		slog.Group("Logging",
+			slog.String("Level", c.Logging.Level),
+			slog.String("Format", c.Logging.Format),
+		),
+		slog.String("TracesExporter", c.TracesExporter),
+	)
+}
+
+// redactValues keeps the names of headers, which may carry secrets
+func redactValues(headers map[string]string) map[string]string {
+	redactedHeaders := make(map[string]string, len(headers))
+	for name := range headers {
+		redactedHeaders[name] = redacted
+	}
+	return redactedHeaders
+}
+
+// Enabled reports whether connections to Temporal use TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertFile != "" || c.InsecureSkipVerify
+}
+
+// Load reads the CA and client certificate into a TLS configuration, which
+// reloads the client certificate when its files change
+func (c TLSConfig) Load() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		reloader, err := newCertReloader(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.GetClientCertificate = reloader.GetClientCertificate
 	}
+
+	return config, nil
 }
 
+// WorkerOptions returns the options of each worker. The sticky cache size
+// is process-wide and set by NewEmbeddedWorker.
 func (c *Config) WorkerOptions() worker.Options {
 	return worker.Options{
-		MaxConcurrentWorkflowTaskPollers:    c.MaxConcurrentWorkflows,
-		MaxConcurrentActivityTaskPollers:    c.MaxConcurrentActivities,
-		WorkerStopTimeout:                   c.WorkerStopTimeout,
-
-		// Enable worker versioning for safe deployments
-		EnableSessionWorker: true,
-		
-		// Enable structured logging
+		MaxConcurrentWorkflowTaskExecutionSize:  c.MaxConcurrentWorkflowTasks,
+		MaxConcurrentActivityExecutionSize:      c.MaxConcurrentActivities,
+		MaxConcurrentLocalActivityExecutionSize: c.MaxConcurrentLocalActivities,
+		MaxConcurrentWorkflowTaskPollers:        c.WorkflowTaskPollers,
+		MaxConcurrentActivityTaskPollers:        c.ActivityTaskPollers,
+		WorkerActivitiesPerSecond:               c.WorkerActivitiesPerSecond,
+		TaskQueueActivitiesPerSecond:            c.TaskQueueActivitiesPerSecond,
+		WorkerStopTimeout:                       c.WorkerStopTimeout,
+
+		EnableSessionWorker:               c.Sessions.Enabled,
+		MaxConcurrentSessionExecutionSize: c.Sessions.MaxConcurrent,
+
+		BuildID:                 c.Versioning.BuildID,
+		UseBuildIDForVersioning: c.Versioning.UseBuildID,
+
+		// Workflow logs are not repeated when a workflow is replayed
 		EnableLoggingInReplay: false,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,262 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// env serves environment variables from a map
+func env(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config, err := Load("astral", nil, env(nil))
+		require.NoError(t, err)
+		assert.Equal(t, DefaultConfig(), config)
+	})
+
+	t.Run("flags override the environment, which overrides the file", func(t *testing.T) {
+		path := writeConfigFile(t, `
+hostPort: temporal.internal:7233
+namespace: payments
+taskQueue: from-file
+taskQueues:
+  payment: payments
+workerStopTimeout: 1m
+api:
+  addr: ":8000"
+logging:
+  format: json
+`)
+		config, err := Load("astral", []string{"-task-queue", "from-flag", "-log-level", "debug"}, env(map[string]string{
+			"CONFIG_FILE":                          path,
+			"TEMPORAL_TASK_QUEUE":                  "from-env",
+			"WORKER_MAX_CONCURRENT_WORKFLOW_TASKS": "20",
+			"HEALTH_ADDR":                          ":9100",
+		}))
+		require.NoError(t, err)
+
+		assert.Equal(t, "temporal.internal:7233", config.HostPort)
+		assert.Equal(t, "payments", config.Namespace)
+		assert.Equal(t, "from-flag", config.TaskQueue)
+		assert.Equal(t, map[string]string{"payment": "payments"}, config.TaskQueues)
+		assert.Equal(t, 20, config.MaxConcurrentWorkflowTasks)
+		assert.Equal(t, 10, config.MaxConcurrentActivities)
+		assert.Equal(t, time.Minute, config.WorkerStopTimeout)
+		assert.Equal(t, ":9100", config.HealthAddr)
+		assert.Equal(t, ":8000", config.API.Addr)
+		assert.Equal(t, "debug", config.Logging.Level)
+		assert.Equal(t, "json", config.Logging.Format)
+	})
+
+	t.Run("config flag", func(t *testing.T) {
+		path := writeConfigFile(t, "namespace: from-flag-file\n")
+		config, err := Load("astral", []string{"-config", path}, env(map[string]string{"CONFIG_FILE": "missing.yaml"}))
+		require.NoError(t, err)
+		assert.Equal(t, "from-flag-file", config.Namespace)
+	})
+
+	t.Run("worker tuning", func(t *testing.T) {
+		path := writeConfigFile(t, `
+workerActivitiesPerSecond: 50
+versioning:
+  buildId: "1.4.0"
+`)
+		config, err := Load("astral", []string{"-activity-task-pollers", "4", "-use-build-id-versioning"}, env(map[string]string{
+			"CONFIG_FILE":                             path,
+			"WORKER_TASK_QUEUE_ACTIVITIES_PER_SECOND": "2.5",
+		}))
+		require.NoError(t, err)
+
+		options := config.WorkerOptions()
+		assert.Equal(t, 10, options.MaxConcurrentWorkflowTaskExecutionSize)
+		assert.Equal(t, 10, options.MaxConcurrentActivityExecutionSize)
+		assert.Equal(t, 2, options.MaxConcurrentWorkflowTaskPollers)
+		assert.Equal(t, 4, options.MaxConcurrentActivityTaskPollers)
+		assert.Equal(t, 50.0, options.WorkerActivitiesPerSecond)
+		assert.Equal(t, 2.5, options.TaskQueueActivitiesPerSecond)
+		assert.False(t, options.EnableSessionWorker)
+		assert.Equal(t, "1.4.0", options.BuildID)
+		assert.True(t, options.UseBuildIDForVersioning)
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		config, err := Load("astral", []string{"-temporal-tls-insecure-skip-verify"}, env(map[string]string{
+			"TEMPORAL_API_KEY": "key-1",
+			"TEMPORAL_HEADERS": "x-tenant=astral, x-region=eu",
+		}))
+		require.NoError(t, err)
+
+		assert.True(t, config.TLS.InsecureSkipVerify)
+		assert.True(t, config.TLS.Enabled())
+		assert.Equal(t, "key-1", config.Credentials.APIKey)
+		assert.Equal(t, map[string]string{"x-tenant": "astral", "x-region": "eu"}, config.Credentials.Headers)
+	})
+
+	t.Run("run mode", func(t *testing.T) {
+		config, err := Load("astral", []string{"-mode", "worker"}, env(map[string]string{"RUN_MODE": "api"}))
+		require.NoError(t, err)
+		assert.True(t, config.RunsWorker())
+		assert.False(t, config.RunsAPI())
+
+		// Only the API listens on api.addr
+		config, err = Load("astral", nil, env(map[string]string{"RUN_MODE": "worker", "API_ADDR": ":9090"}))
+		require.NoError(t, err)
+		assert.Equal(t, ModeWorker, config.Mode)
+
+		config, err = Load("astral", nil, env(map[string]string{"RUN_MODE": "api"}))
+		require.NoError(t, err)
+		assert.False(t, config.RunsWorker())
+		assert.True(t, config.RunsAPI())
+	})
+
+	t.Run("per-domain task queues", func(t *testing.T) {
+		config, err := Load("astral", nil, env(map[string]string{"TEMPORAL_TASK_QUEUES": "payment=payments, order=orders,checkout=orders"}))
+		require.NoError(t, err)
+
+		assert.Equal(t, "payments", config.TaskQueueFor("payment"))
+		assert.Equal(t, "orders", config.TaskQueueFor("order"))
+		assert.Equal(t, "microservice-task-queue", config.TaskQueueFor("inventory"))
+		assert.Equal(t, []string{"microservice-task-queue", "orders", "payments"}, config.AllTaskQueues())
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := Load("astral", nil, env(map[string]string{"WORKER_STOP_TIMEOUT": "soon"}))
+		assert.EqualError(t, err, `invalid WORKER_STOP_TIMEOUT: "soon" is not a duration`)
+
+		_, err = Load("astral", nil, env(map[string]string{"WORKER_SESSIONS": "sometimes"}))
+		assert.EqualError(t, err, `invalid WORKER_SESSIONS: "sometimes" is not a boolean`)
+
+		_, err = Load("astral", []string{"-task-queues", "payment"}, env(nil))
+		assert.EqualError(t, err, `invalid -task-queues: "payment" is not a domain=taskQueue pair`)
+
+		_, err = Load("astral", nil, env(map[string]string{"CONFIG_FILE": writeConfigFile(t, "namespace: [")}))
+		assert.ErrorContains(t, err, "failed to parse config file")
+	})
+
+	t.Run("misspelled key", func(t *testing.T) {
+		_, err := Load("astral", nil, env(map[string]string{"CONFIG_FILE": writeConfigFile(t, "namespace: payments\ntaskQueus:\n  payment: payments\n")}))
+		assert.ErrorContains(t, err, "failed to parse config file")
+		assert.ErrorContains(t, err, "field taskQueus not found")
+
+		_, err = Load("astral", nil, env(map[string]string{"CONFIG_FILE": writeConfigFile(t, "api:\n  adr: \":8000\"\n")}))
+		assert.ErrorContains(t, err, "field adr not found")
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		config, err := Load("astral", nil, env(map[string]string{"CONFIG_FILE": writeConfigFile(t, "")}))
+		require.NoError(t, err)
+		assert.Equal(t, DefaultConfig(), config)
+	})
+
+	t.Run("help", func(t *testing.T) {
+		_, err := Load("astral", []string{"-help"}, env(nil))
+		assert.True(t, errors.Is(err, flag.ErrHelp))
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	config := DefaultConfig()
+	config.Namespace = ""
+	config.MaxConcurrentActivities = 0
+	config.WorkflowTaskPollers = 1
+	config.ActivityTaskPollers = 20
+	config.WorkerActivitiesPerSecond = -1
+	config.Sessions.Enabled = true
+	config.Versioning.UseBuildID = true
+	config.TLS.CertFile = "client.pem"
+	config.Credentials.APIKey = "key-1"
+	config.Credentials.Headers = map[string]string{"Authorization": "Bearer key-2"}
+	config.HealthAddr = config.API.Addr
+	config.Logging.Format = "xml"
+	config.TracesExporter = "jaeger"
+
+	err := config.Validate()
+	require.Error(t, err)
+	for _, message := range []string{
+		"namespace is required",
+		"maxConcurrentActivities must be positive, got 0",
+		"workflowTaskPollers must be at least 2, got 1",
+		"activityTaskPollers (20) must not exceed maxConcurrentActivities (0)",
+		"workerActivitiesPerSecond must not be negative, got -1",
+		"versioning.buildId is required with versioning.useBuildId",
+		"sessions cannot be enabled with versioning.useBuildId",
+		"tls.certFile and tls.keyFile must be set together",
+		"credentials.apiKey and an authorization header cannot both be set",
+		`healthAddr and api.addr must differ, both are ":8080"`,
+		`logging: unknown log format "xml"`,
+		`unknown trace exporter "jaeger"`,
+	} {
+		assert.ErrorContains(t, err, message)
+	}
+
+	config = DefaultConfig()
+	config.Mode = "scheduler"
+	assert.ErrorContains(t, config.Validate(), `mode must be all, worker or api, got "scheduler"`)
+
+	assert.NoError(t, DefaultConfig().Validate())
+}
+
+func TestConfig_CheckDomains(t *testing.T) {
+	config := DefaultConfig()
+	config.TaskQueues = map[string]string{"payment": "payments", "paymnet": "payments", "shiping": "shipping"}
+
+	assert.EqualError(t, config.CheckDomains([]string{"order", "payment"}),
+		"invalid configuration: taskQueues names unknown domains paymnet, shiping, known domains are order, payment")
+
+	config.TaskQueues = map[string]string{"payment": "payments"}
+	assert.NoError(t, config.CheckDomains([]string{"order", "payment"}))
+	assert.NoError(t, DefaultConfig().CheckDomains(nil))
+}
+
+func TestConfig_LogValue(t *testing.T) {
+	config := DefaultConfig()
+	config.API.APIKeys = "key1:ci-bot:order:write"
+	config.API.JWTSecret = "s3cret"
+	config.Credentials.APIKey = "temporal-key"
+	config.Credentials.Headers = map[string]string{"x-tenant-token": "tenant-secret"}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("Loaded configuration", "Config", config)
+
+	assert.Contains(t, buf.String(), "Config.HostPort=localhost:7233")
+	assert.Contains(t, buf.String(), "Config.API.APIKeys=[REDACTED]")
+	assert.Contains(t, buf.String(), "Config.API.JWTSecret=[REDACTED]")
+	assert.Contains(t, buf.String(), "Config.Credentials.APIKey=[REDACTED]")
+	assert.Contains(t, buf.String(), "x-tenant-token:[REDACTED]")
+	assert.NotContains(t, buf.String(), "s3cret")
+	assert.NotContains(t, buf.String(), "temporal-key")
+	assert.NotContains(t, buf.String(), "tenant-secret")
+	assert.NotContains(t, buf.String(), "ci-bot")
+}
+
+func TestTLSConfig_Load(t *testing.T) {
+	assert.False(t, TLSConfig{}.Enabled())
+	assert.True(t, TLSConfig{CAFile: "ca.pem"}.Enabled())
+	assert.True(t, TLSConfig{InsecureSkipVerify: true}.Enabled())
+
+	_, err := TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}.Load()
+	assert.ErrorContains(t, err, "failed to read CA file")
+
+	_, err = TLSConfig{CAFile: writeConfigFile(t, "not a certificate")}.Load()
+	assert.ErrorContains(t, err, "no certificates found in CA file")
+}
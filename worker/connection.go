@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"simple-temporal-workflow/metrics"
+	"simple-temporal-workflow/tracing"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+)
+
+// Dial connects to Temporal as configured: over TLS when it is enabled or
+// an API key is set, sending the credentials with every request, logging
+// through logger, recording the SDK's metrics and tracing workflow starts,
+// workflows and activities
+func Dial(config *Config, logger *slog.Logger) (client.Client, error) {
+	options := client.Options{
+		HostPort:       config.HostPort,
+		Namespace:      config.Namespace,
+		Logger:         log.NewStructuredLogger(logger),
+		MetricsHandler: metrics.NewTemporalHandler(metrics.Default),
+		Interceptors:   []interceptor.ClientInterceptor{tracing.NewInterceptor()},
+	}
+
+	// API keys are bearer secrets, never sent in plaintext
+	if config.TLS.Enabled() || config.Credentials.APIKey != "" {
+		tlsConfig, err := config.TLS.Load()
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.InsecureSkipVerify {
+			logger.Warn("Temporal server certificate is not verified, for development only", "HostPort", config.HostPort)
+		}
+		options.ConnectionOptions.TLS = tlsConfig
+	}
+	if headers := config.Credentials.headers(); len(headers) > 0 {
+		options.HeadersProvider = headers
+	}
+
+	c, err := client.Dial(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporal client: %w", err)
+	}
+	return c, nil
+}
+
+// requestHeaders are sent with every request to Temporal
+type requestHeaders map[string]string
+
+func (h requestHeaders) GetHeaders(ctx context.Context) (map[string]string, error) {
+	return h, nil
+}
+
+// headers returns the configured headers and the API key as a bearer token,
+// with the lower-case names gRPC requires
+func (c CredentialsConfig) headers() requestHeaders {
+	headers := make(requestHeaders, len(c.Headers)+1)
+	for name, value := range c.Headers {
+		headers[strings.ToLower(name)] = value
+	}
+	if c.APIKey != "" {
+		headers["authorization"] = "Bearer " + c.APIKey
+	}
+	return headers
+}
+
+// certReloader serves the client certificate to TLS handshakes, reloading
+// it once its files change so that rotated certificates are used by the
+// next connection without a restart
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time // Of the newest of the two files when loaded
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// modified returns the modification time of the newest of the files
+func (r *certReloader) modified() (time.Time, error) {
+	var newest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, nil
+}
+
+func (r *certReloader) reload() error {
+	modTime, err := r.modified()
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// GetClientCertificate reloads the certificate when its files changed. A
+// certificate that fails to load, such as one whose key is not written yet,
+// is retried by the next handshake, which uses the previous one meanwhile.
+func (r *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if modTime, err := r.modified(); err != nil || !modTime.Equal(r.modTime) {
+		if err := r.reload(); err != nil {
+			slog.Warn("Failed to reload client certificate, using the previous one", "CertFile", r.certFile, "Error", err)
+		} else {
+			slog.Info("Reloaded client certificate", "CertFile", r.certFile)
+		}
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate writes a self-signed client certificate and its key,
+// dated modTime
+func writeCertificate(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, cert *tls.Certificate) string {
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return parsed.Subject.CommonName
+}
+
+func TestTLSConfig_ReloadsClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	rotated := time.Now().Truncate(time.Second)
+	writeCertificate(t, certFile, keyFile, "worker-v1", rotated.Add(-time.Hour))
+
+	config, err := TLSConfig{CertFile: certFile, KeyFile: keyFile}.Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.Certificates)
+
+	cert, err := config.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "worker-v1", commonName(t, cert))
+
+	writeCertificate(t, certFile, keyFile, "worker-v2", rotated)
+	cert, err = config.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "worker-v2", commonName(t, cert))
+
+	// A certificate whose key is not written yet keeps the previous one
+	require.NoError(t, os.WriteFile(keyFile, []byte("partial"), 0o600))
+	cert, err = config.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "worker-v2", commonName(t, cert))
+}
+
+func TestCredentialsConfig_Headers(t *testing.T) {
+	assert.Empty(t, CredentialsConfig{}.headers())
+
+	headers, err := CredentialsConfig{
+		APIKey:  "key-1",
+		Headers: map[string]string{"X-Tenant": "astral"},
+	}.headers().GetHeaders(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer key-1", "x-tenant": "astral"}, headers)
+}
@@ -0,0 +1,297 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"simple-temporal-workflow/metrics"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// readyTimeout bounds the checks of a single readiness probe
+const readyTimeout = 5 * time.Second
+
+// Check statuses reported by the ready endpoint
+const (
+	CheckPass = "pass"
+	CheckFail = "fail"
+)
+
+// Names of the worker, API and Temporal checks, which run ahead of the
+// domain checks
+const (
+	CheckWorker    = "worker"
+	CheckAPI       = "api"
+	CheckTemporal  = "temporal"
+	CheckNamespace = "namespace"
+)
+
+// HealthCheck reports whether a dependency of the service is healthy
+type HealthCheck func(ctx context.Context) error
+
+type namedHealthCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthServer serves the liveness, readiness and metrics endpoints. Each
+// run mode adds the checks of the parts it runs.
+type HealthServer struct {
+	addr       string
+	metrics    *metrics.Registry
+	logger     *slog.Logger
+	httpServer *http.Server
+
+	mu       sync.RWMutex
+	liveness []namedHealthCheck
+	checks   []namedHealthCheck
+}
+
+// NewHealthServer creates a health server listening on addr once started
+func NewHealthServer(addr string, registry *metrics.Registry, logger *slog.Logger) *HealthServer {
+	return &HealthServer{addr: addr, metrics: registry, logger: logger}
+}
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessReport is the body of the ready endpoint
+type ReadinessReport struct {
+	Status string        `json:"status"` // "ready" when every check passed
+	Checks []CheckResult `json:"checks"`
+}
+
+// AddLivenessCheck adds a check failing the health endpoint, for failures
+// only restarting the process recovers from. The ready endpoint does not run
+// it, so a readiness check must cover the same failure.
+func (h *HealthServer) AddLivenessCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, namedHealthCheck{name: name, check: check})
+}
+
+// AddHealthCheck adds a named check run by the ready endpoint
+func (h *HealthServer) AddHealthCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedHealthCheck{name: name, check: check})
+}
+
+// AddTemporalChecks adds the checks of the Temporal frontend and namespace
+func (h *HealthServer) AddTemporalChecks(c client.Client, namespace string) {
+	h.AddHealthCheck(CheckTemporal, temporalCheck(c))
+	h.AddHealthCheck(CheckNamespace, namespaceCheck(c, namespace))
+}
+
+// Readiness runs the readiness checks concurrently and reports each one's
+// outcome, in order
+func (h *HealthServer) Readiness(ctx context.Context) ReadinessReport {
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	report := ReadinessReport{Status: "ready", Checks: make([]CheckResult, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedHealthCheck) {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != CheckPass {
+			report.Status = "not_ready"
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, c namedHealthCheck) CheckResult {
+	start := time.Now()
+	err := c.check(ctx)
+	result := CheckResult{
+		Name:      c.name,
+		Status:    CheckPass,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = CheckFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// CheckAlive is the liveness of the worker, failing only once it stopped on
+// a fatal error. A worker that is still starting is alive.
+func (w *EmbeddedWorker) CheckAlive(ctx context.Context) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.fatalErr != nil {
+		return fmt.Errorf("worker stopped: %w", w.fatalErr)
+	}
+	return nil
+}
+
+// CheckWorker is the readiness of the worker, failing until its pollers are
+// started and once it stopped on a fatal error
+func (w *EmbeddedWorker) CheckWorker(ctx context.Context) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	switch {
+	case w.fatalErr != nil:
+		return fmt.Errorf("worker stopped: %w", w.fatalErr)
+	case !w.running:
+		return errors.New("worker is not running")
+	}
+	return nil
+}
+
+// ListenerCheck is the readiness of a server run by the service, failing
+// until the server listens and once it stopped serving
+type ListenerCheck struct {
+	mu        sync.RWMutex
+	listening bool
+	err       error
+}
+
+// Listening records that the server accepts connections
+func (c *ListenerCheck) Listening() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listening = true
+}
+
+// Stopped records that the server stopped serving on err
+func (c *ListenerCheck) Stopped(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listening = false
+	c.err = err
+}
+
+// Check implements HealthCheck
+func (c *ListenerCheck) Check(ctx context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	switch {
+	case c.err != nil:
+		return fmt.Errorf("server stopped: %w", c.err)
+	case !c.listening:
+		return errors.New("server is not listening")
+	}
+	return nil
+}
+
+// temporalCheck checks that the Temporal frontend is reachable and serving
+func temporalCheck(c client.Client) HealthCheck {
+	return func(ctx context.Context) error {
+		_, err := c.CheckHealth(ctx, &client.CheckHealthRequest{})
+		return err
+	}
+}
+
+// namespaceCheck checks that the worker's namespace exists and is active
+func namespaceCheck(c client.Client, namespace string) HealthCheck {
+	return func(ctx context.Context) error {
+		resp, err := c.WorkflowService().DescribeNamespace(ctx, &workflowservice.DescribeNamespaceRequest{Namespace: namespace})
+		if err != nil {
+			return err
+		}
+		if state := resp.GetNamespaceInfo().GetState(); state != enums.NAMESPACE_STATE_REGISTERED {
+			return fmt.Errorf("namespace %s is %s", namespace, state)
+		}
+		return nil
+	}
+}
+
+// handleHealth is the liveness probe. It does not call dependencies, which
+// the ready endpoint covers, and only fails when a liveness check does,
+// such as once the worker stopped on a fatal error; a service that is still
+// starting is alive.
+func (h *HealthServer) handleHealth(rw http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	liveness := h.liveness
+	h.mu.RUnlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	for _, c := range liveness {
+		if err := c.check(r.Context()); err != nil {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(rw).Encode(map[string]string{"status": "unhealthy", "error": err.Error()})
+			return
+		}
+	}
+	json.NewEncoder(rw).Encode(map[string]string{"status": "healthy"})
+}
+
+// handleReady is the readiness probe, listing the outcome of every check
+func (h *HealthServer) handleReady(rw http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	report := h.Readiness(ctx)
+	rw.Header().Set("Content-Type", "application/json")
+	if report.Status != "ready" {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(rw).Encode(report)
+}
+
+// Handler serves the liveness, readiness and metrics endpoints
+func (h *HealthServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Liveness and readiness endpoints
+	mux.HandleFunc("/health", h.handleHealth)
+	mux.HandleFunc("/ready", h.handleReady)
+
+	// Metrics endpoint: SDK, workflow start and API metrics
+	mux.Handle("/metrics", h.metrics.Handler())
+	return mux
+}
+
+// Start serves the endpoints in the background
+func (h *HealthServer) Start() {
+	h.httpServer = &http.Server{
+		Addr:    h.addr,
+		Handler: h.Handler(),
+	}
+
+	go func() {
+		h.logger.Info("Starting health server", "Addr", h.httpServer.Addr)
+		if err := h.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			h.logger.Error("Health server error", "Error", err)
+		}
+	}()
+}
+
+// Shutdown stops serving the endpoints, waiting for open requests until
+// ctx is done
+func (h *HealthServer) Shutdown(ctx context.Context) error {
+	if h.httpServer == nil {
+		return nil
+	}
+	if err := h.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop health server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/namespace/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"google.golang.org/grpc"
+	"simple-temporal-workflow/metrics"
+)
+
+// healthClient answers the health and namespace checks
+type healthClient struct {
+	client.Client
+	healthErr      error
+	namespaceState enums.NamespaceState
+}
+
+func (c *healthClient) CheckHealth(ctx context.Context, request *client.CheckHealthRequest) (*client.CheckHealthResponse, error) {
+	return &client.CheckHealthResponse{}, c.healthErr
+}
+
+func (c *healthClient) Close() {}
+
+func (c *healthClient) WorkflowService() workflowservice.WorkflowServiceClient {
+	return &namespaceService{state: c.namespaceState}
+}
+
+type namespaceService struct {
+	workflowservice.WorkflowServiceClient
+	state enums.NamespaceState
+}
+
+func (s *namespaceService) DescribeNamespace(ctx context.Context, in *workflowservice.DescribeNamespaceRequest, opts ...grpc.CallOption) (*workflowservice.DescribeNamespaceResponse, error) {
+	return &workflowservice.DescribeNamespaceResponse{
+		NamespaceInfo: &namespace.NamespaceInfo{Name: in.Namespace, State: s.state},
+	}, nil
+}
+
+func newHealthWorker(c *healthClient) *EmbeddedWorker {
+	return &EmbeddedWorker{
+		config:  DefaultConfig(),
+		client:  c,
+		running: true,
+		errs:    make(chan error, 1),
+		metrics: metrics.NewRegistry(),
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func probe(t *testing.T, handler http.HandlerFunc) (int, map[string]any) {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+// newHealthServer runs the checks of a worker run in ModeAll or ModeWorker
+func newHealthServer(w *EmbeddedWorker) *HealthServer {
+	h := NewHealthServer(w.config.HealthAddr, w.metrics, w.logger)
+	h.AddLivenessCheck(CheckWorker, w.CheckAlive)
+	h.AddHealthCheck(CheckWorker, w.CheckWorker)
+	h.AddTemporalChecks(w.client, w.config.Namespace)
+	return h
+}
+
+func TestHealthServer_Readiness(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		h := newHealthServer(newHealthWorker(&healthClient{namespaceState: enums.NAMESPACE_STATE_REGISTERED}))
+		h.AddHealthCheck("payment", func(ctx context.Context) error { return nil })
+
+		report := h.Readiness(context.Background())
+		assert.Equal(t, "ready", report.Status)
+		require.Len(t, report.Checks, 4)
+		for i, name := range []string{CheckWorker, CheckTemporal, CheckNamespace, "payment"} {
+			assert.Equal(t, name, report.Checks[i].Name)
+			assert.Equal(t, CheckPass, report.Checks[i].Status)
+			assert.Empty(t, report.Checks[i].Error)
+		}
+
+		status, body := probe(t, h.handleReady)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "ready", body["status"])
+	})
+
+	t.Run("reports each failure", func(t *testing.T) {
+		h := newHealthServer(newHealthWorker(&healthClient{
+			healthErr:      errors.New("connection refused"),
+			namespaceState: enums.NAMESPACE_STATE_DEPRECATED,
+		}))
+		h.AddHealthCheck("payment", func(ctx context.Context) error { return errors.New("gateway unreachable") })
+
+		report := h.Readiness(context.Background())
+		assert.Equal(t, "not_ready", report.Status)
+		assert.Equal(t, CheckResult{Name: CheckWorker, Status: CheckPass, LatencyMs: report.Checks[0].LatencyMs}, report.Checks[0])
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+		assert.Equal(t, "namespace claude is Deprecated", report.Checks[2].Error)
+		assert.Equal(t, "gateway unreachable", report.Checks[3].Error)
+
+		status, body := probe(t, h.handleReady)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "not_ready", body["status"])
+		assert.Len(t, body["checks"], 4)
+	})
+
+	t.Run("not ready before the worker starts", func(t *testing.T) {
+		w := newHealthWorker(&healthClient{namespaceState: enums.NAMESPACE_STATE_REGISTERED})
+		w.running = false
+
+		report := newHealthServer(w).Readiness(context.Background())
+		assert.Equal(t, "not_ready", report.Status)
+		assert.Equal(t, "worker is not running", report.Checks[0].Error)
+	})
+
+	t.Run("api mode checks the listener and Temporal", func(t *testing.T) {
+		var listener ListenerCheck
+		h := NewHealthServer(":9090", metrics.NewRegistry(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+		h.AddHealthCheck(CheckAPI, listener.Check)
+		h.AddTemporalChecks(&healthClient{namespaceState: enums.NAMESPACE_STATE_REGISTERED}, "claude")
+
+		report := h.Readiness(context.Background())
+		assert.Equal(t, "not_ready", report.Status)
+		assert.Equal(t, "server is not listening", report.Checks[0].Error)
+
+		listener.Listening()
+		report = h.Readiness(context.Background())
+		assert.Equal(t, "ready", report.Status)
+		require.Len(t, report.Checks, 3)
+		for i, name := range []string{CheckAPI, CheckTemporal, CheckNamespace} {
+			assert.Equal(t, name, report.Checks[i].Name)
+		}
+
+		listener.Stopped(errors.New("address already in use"))
+		report = h.Readiness(context.Background())
+		assert.Equal(t, "server stopped: address already in use", report.Checks[0].Error)
+
+		status, body := probe(t, h.handleHealth)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "healthy", body["status"])
+	})
+}
+
+func TestHealthServer_Liveness(t *testing.T) {
+	w := newHealthWorker(&healthClient{healthErr: errors.New("connection refused")})
+	h := newHealthServer(w)
+
+	// Dependencies do not affect liveness
+	status, body := probe(t, h.handleHealth)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "healthy", body["status"])
+
+	// A worker that is still starting is alive, only not ready
+	w.running = false
+	status, _ = probe(t, h.handleHealth)
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = probe(t, h.handleReady)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	// A worker that stopped on its own is not coming back
+	w.fail(errors.New("namespace not found"))
+	assert.False(t, w.IsRunning())
+
+	status, body = probe(t, h.handleHealth)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "unhealthy", body["status"])
+	assert.Equal(t, "worker stopped: namespace not found", body["error"])
+}
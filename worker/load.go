@@ -0,0 +1,248 @@
+package worker
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is a configuration value settable through a flag and an
+// environment variable
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	value flag.Value
+}
+
+// settings lists every setting of c, bound to its fields
+func (c *Config) settings() []setting {
+	return []setting{
+		{"mode", "RUN_MODE", "all, worker or api", (*stringValue)(&c.Mode)},
+		{"temporal-host-port", "TEMPORAL_HOST_PORT", "Temporal frontend address", (*stringValue)(&c.HostPort)},
+		{"temporal-namespace", "TEMPORAL_NAMESPACE", "Temporal namespace", (*stringValue)(&c.Namespace)},
+		{"temporal-tls-ca-file", "TEMPORAL_TLS_CA_FILE", "CA bundle verifying the Temporal server", (*stringValue)(&c.TLS.CAFile)},
+		{"temporal-tls-cert-file", "TEMPORAL_TLS_CERT_FILE", "client certificate for mTLS", (*stringValue)(&c.TLS.CertFile)},
+		{"temporal-tls-key-file", "TEMPORAL_TLS_KEY_FILE", "key of the client certificate", (*stringValue)(&c.TLS.KeyFile)},
+		{"temporal-tls-server-name", "TEMPORAL_TLS_SERVER_NAME", "name verified on the server certificate", (*stringValue)(&c.TLS.ServerName)},
+		{"temporal-tls-insecure-skip-verify", "TEMPORAL_TLS_INSECURE_SKIP_VERIFY", "accept any server certificate, for development only", (*boolValue)(&c.TLS.InsecureSkipVerify)},
+		{"temporal-api-key", "TEMPORAL_API_KEY", "API key sent as a bearer token", (*stringValue)(&c.Credentials.APIKey)},
+		{"temporal-headers", "TEMPORAL_HEADERS", "headers sent with every request, e.g. x-tenant=astral", (*headersValue)(&c.Credentials.Headers)},
+		{"task-queue", "TEMPORAL_TASK_QUEUE", "default task queue", (*stringValue)(&c.TaskQueue)},
+		{"task-queues", "TEMPORAL_TASK_QUEUES", "per-domain task queues, e.g. payment=payments,order=orders", (*taskQueuesValue)(&c.TaskQueues)},
+		{"max-concurrent-workflow-tasks", "WORKER_MAX_CONCURRENT_WORKFLOW_TASKS", "workflow task execution slots", (*intValue)(&c.MaxConcurrentWorkflowTasks)},
+		{"max-concurrent-activities", "WORKER_MAX_CONCURRENT_ACTIVITIES", "activity execution slots", (*intValue)(&c.MaxConcurrentActivities)},
+		{"max-concurrent-local-activities", "WORKER_MAX_CONCURRENT_LOCAL_ACTIVITIES", "local activity execution slots", (*intValue)(&c.MaxConcurrentLocalActivities)},
+		{"workflow-task-pollers", "WORKER_WORKFLOW_TASK_POLLERS", "workflow task pollers, at least 2", (*intValue)(&c.WorkflowTaskPollers)},
+		{"activity-task-pollers", "WORKER_ACTIVITY_TASK_POLLERS", "activity task pollers", (*intValue)(&c.ActivityTaskPollers)},
+		{"worker-activities-per-second", "WORKER_ACTIVITIES_PER_SECOND", "activities each worker starts per second, 0 for no limit", (*floatValue)(&c.WorkerActivitiesPerSecond)},
+		{"task-queue-activities-per-second", "WORKER_TASK_QUEUE_ACTIVITIES_PER_SECOND", "activities the task queue starts per second, 0 for no limit", (*floatValue)(&c.TaskQueueActivitiesPerSecond)},
+		{"sticky-cache-size", "WORKER_STICKY_CACHE_SIZE", "workflows cached between their tasks", (*intValue)(&c.StickyCacheSize)},
+		{"sessions", "WORKER_SESSIONS", "enable sessions", (*boolValue)(&c.Sessions.Enabled)},
+		{"max-concurrent-sessions", "WORKER_MAX_CONCURRENT_SESSIONS", "sessions each worker runs at once", (*intValue)(&c.Sessions.MaxConcurrent)},
+		{"build-id", "WORKER_BUILD_ID", "build ID of the worker", (*stringValue)(&c.Versioning.BuildID)},
+		{"use-build-id-versioning", "WORKER_USE_BUILD_ID_VERSIONING", "only take tasks compatible with the build ID", (*boolValue)(&c.Versioning.UseBuildID)},
+		{"worker-stop-timeout", "WORKER_STOP_TIMEOUT", "time given to running activities on shutdown", (*durationValue)(&c.WorkerStopTimeout)},
+		{"health-addr", "HEALTH_ADDR", "listen address of the health endpoints", (*stringValue)(&c.HealthAddr)},
+		{"api-addr", "API_ADDR", "listen address of the API", (*stringValue)(&c.API.Addr)},
+		{"api-keys", "API_KEYS", "API keys, e.g. key1:ci-bot:order:write|payment:write", (*stringValue)(&c.API.APIKeys)},
+		{"jwt-hmac-secret", "JWT_HMAC_SECRET", "HMAC secret of API bearer tokens", (*stringValue)(&c.API.JWTSecret)},
+		{"jwt-issuer", "JWT_ISSUER", "required issuer of API bearer tokens", (*stringValue)(&c.API.JWTIssuer)},
+		{"jwt-audience", "JWT_AUDIENCE", "required audience of API bearer tokens", (*stringValue)(&c.API.JWTAudience)},
+		{"log-level", "LOG_LEVEL", "debug, info, warn or error", (*stringValue)(&c.Logging.Level)},
+		{"log-format", "LOG_FORMAT", "text or json", (*stringValue)(&c.Logging.Format)},
+		{"traces-exporter", "OTEL_TRACES_EXPORTER", "none, stdout or otlp", (*stringValue)(&c.TracesExporter)},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence, the
+// defaults, a YAML file, environment variables and command-line flags, and
+// validates it. The file is named by the -config flag or CONFIG_FILE. Run
+// with -help to list the settings.
+func Load(name string, args []string, getenv func(string) string) (*Config, error) {
+	// Flags are applied last, so only collect them for now
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", getenv("CONFIG_FILE"), "YAML configuration file (env CONFIG_FILE)")
+	set := make(map[string]string)
+	for _, s := range DefaultConfig().settings() {
+		_, isBool := s.value.(*boolValue)
+		flags.Var(&pendingValue{name: s.flag, set: set, isBool: isBool}, s.flag, fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.value))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+	if *configFile != "" {
+		if err := config.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range config.settings() {
+		if value := getenv(s.env); value != "" {
+			if err := s.value.Set(value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+	for _, s := range config.settings() {
+		if value, ok := set[s.flag]; ok {
+			if err := s.value.Set(value); err != nil {
+				return nil, fmt.Errorf("invalid -%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadFile overrides the settings present in a YAML file. Unknown keys are
+// rejected, so a misspelled setting fails instead of keeping its default.
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	// An empty file sets nothing
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// pendingValue records a flag for Load to apply once the file and the
+// environment are
+type pendingValue struct {
+	name   string
+	set    map[string]string
+	isBool bool
+}
+
+func (v *pendingValue) String() string     { return "" }
+func (v *pendingValue) Set(s string) error { v.set[v.name] = s; return nil }
+func (v *pendingValue) IsBoolFlag() bool   { return v.isBool }
+
+type stringValue string
+
+func (v *stringValue) String() string     { return string(*v) }
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+
+type intValue int
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+type floatValue float64
+
+func (v *floatValue) String() string { return strconv.FormatFloat(float64(*v), 'g', -1, 64) }
+
+func (v *floatValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", s)
+	}
+	*v = floatValue(f)
+	return nil
+}
+
+type boolValue bool
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", s)
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+type durationValue time.Duration
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration", s)
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+// taskQueuesValue parses domain=taskQueue pairs separated by commas
+type taskQueuesValue map[string]string
+
+func (v *taskQueuesValue) String() string { return formatPairs(*v) }
+
+func (v *taskQueuesValue) Set(s string) error {
+	taskQueues, err := parsePairs(s, "domain=taskQueue")
+	if err != nil {
+		return err
+	}
+	*v = taskQueues
+	return nil
+}
+
+// headersValue parses name=value pairs separated by commas. Header values
+// may be secrets, so the default is not printed.
+type headersValue map[string]string
+
+func (v *headersValue) String() string { return "" }
+
+func (v *headersValue) Set(s string) error {
+	headers, err := parsePairs(s, "name=value")
+	if err != nil {
+		return err
+	}
+	*v = headers
+	return nil
+}
+
+func formatPairs(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for key, value := range m {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// parsePairs parses key=value pairs separated by commas, format naming the
+// pair in errors
+func parsePairs(s, format string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("%q is not a %s pair", pair, format)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
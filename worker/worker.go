@@ -3,51 +3,66 @@ package worker
 import (
 	"context"
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
 	"sync"
-	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"simple-temporal-workflow/metrics"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 )
 
-type RegistrationFunc func(worker.Worker)
+// RegistrationFunc registers the workflows and activities of a task queue
+// with its worker
+type RegistrationFunc func(taskQueue string, w worker.Worker)
+
+// WorkerRunning is set to 1 while the worker is running
+const WorkerRunning = "temporal_worker_running"
 
 type EmbeddedWorker struct {
 	config           *Config
 	client           client.Client
-	worker           worker.Worker
+	taskQueues       []string
+	workers          []worker.Worker // One per task queue
 	registrationFunc RegistrationFunc
-	httpServer       *http.Server
 	mu               sync.RWMutex
 	running          bool
+	fatalErr         error // Set when the worker stopped on its own
+	errs             chan error
+	metrics          *metrics.Registry
+	logger           *slog.Logger
 }
 
-func NewEmbeddedWorker(config *Config, registrationFunc RegistrationFunc) (*EmbeddedWorker, error) {
-	// Create Temporal client
-	c, err := client.Dial(client.Options{
-		HostPort:  config.HostPort,
-		Namespace: config.Namespace,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporal client: %w", err)
-	}
-
-	// Create worker
-	w := worker.New(c, config.TaskQueue, config.WorkerOptions())
-
-	// Register workflows and activities using the provided function
-	registrationFunc(w)
-
-	return &EmbeddedWorker{
+// NewEmbeddedWorker creates the workers of every task queue on a Temporal
+// client created by Dial, which the caller closes once the worker stopped
+func NewEmbeddedWorker(config *Config, c client.Client, logger *slog.Logger, registrationFunc RegistrationFunc) *EmbeddedWorker {
+	ew := &EmbeddedWorker{
 		config:           config,
 		client:           c,
-		worker:           w,
+		taskQueues:       config.AllTaskQueues(),
 		registrationFunc: registrationFunc,
-	}, nil
+		metrics:          metrics.Default,
+		logger:           logger,
+		errs:             make(chan error, 1),
+	}
+
+	// Create a worker per task queue, recording the errors they stop on,
+	// and register workflows and activities using the provided function.
+	// The sticky cache is shared by the workers and sized before they start.
+	worker.SetStickyWorkflowCacheSize(config.StickyCacheSize)
+	workerOptions := config.WorkerOptions()
+	workerOptions.OnFatalError = ew.fail
+	for _, taskQueue := range ew.taskQueues {
+		w := worker.New(c, taskQueue, workerOptions)
+		registrationFunc(taskQueue, w)
+		ew.workers = append(ew.workers, w)
+	}
+
+	return ew
 }
 
+// Start starts the worker's pollers. Errors the worker stops on later are
+// delivered on Errors.
 func (w *EmbeddedWorker) Start(ctx context.Context) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -56,105 +71,105 @@ func (w *EmbeddedWorker) Start(ctx context.Context) error {
 		return fmt.Errorf("worker is already running")
 	}
 
-	// Start health check server
-	w.startHealthServer()
-
-	// Start the worker
-	log.Printf("Starting Temporal worker on task queue: %s", w.config.TaskQueue)
-	
-	go func() {
-		err := w.worker.Run(worker.InterruptCh())
-		if err != nil {
-			log.Printf("Worker error: %v", err)
+	// Start the workers, which return once their pollers are started
+	w.logger.Info("Starting Temporal worker", "TaskQueues", w.taskQueues)
+	for i, sdkWorker := range w.workers {
+		if err := sdkWorker.Start(); err != nil {
+			for _, started := range w.workers[:i] {
+				started.Stop()
+			}
+			return fmt.Errorf("failed to start worker for task queue %s: %w", w.taskQueues[i], err)
 		}
-	}()
+	}
 
 	w.running = true
-	log.Printf("Temporal worker started successfully")
-	
+	w.fatalErr = nil
+	w.setRunning(1)
+	w.logger.Info("Temporal worker started successfully")
+
 	return nil
 }
 
+// Stop stops the worker, letting running activities finish for up to
+// WorkerStopTimeout. It returns early with the context's error once ctx is
+// done, closing the client then fails the polls of the workers left.
 func (w *EmbeddedWorker) Stop(ctx context.Context) error {
 	w.mu.Lock()
-	defer w.mu.Unlock()
-
 	if !w.running {
+		w.mu.Unlock()
 		return nil
 	}
+	w.running = false
+	w.mu.Unlock()
 
-	log.Printf("Stopping Temporal worker...")
+	w.logger.Info("Stopping Temporal worker")
+	w.setRunning(0)
 
-	// Stop worker gracefully
-	w.worker.Stop()
+	// Stop the workers gracefully. The SDK offers no way to bound the wait
+	// other than WorkerStopTimeout, so give up on them once ctx is done.
+	stopped := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for _, sdkWorker := range w.workers {
+			wg.Add(1)
+			go func(sdkWorker worker.Worker) {
+				defer wg.Done()
+				sdkWorker.Stop()
+			}(sdkWorker)
+		}
+		wg.Wait()
+		close(stopped)
+	}()
 
-	// Stop health server
-	if w.httpServer != nil {
-		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
-		w.httpServer.Shutdown(shutdownCtx)
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return fmt.Errorf("worker did not stop in time: %w", ctx.Err())
 	}
-
-	// Close Temporal client
-	w.client.Close()
-
-	w.running = false
-	log.Printf("Temporal worker stopped")
-	
+	w.logger.Info("Temporal worker stopped")
 	return nil
 }
 
+// IsRunning reports whether the worker was started and is still polling
 func (w *EmbeddedWorker) IsRunning() bool {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
-	return w.running
+	return w.running && w.fatalErr == nil
 }
 
-func (w *EmbeddedWorker) GetClient() client.Client {
-	return w.client
+// Errors delivers the error the worker stopped on by itself, such as its
+// namespace being deleted, so the service can shut down. Nothing is sent
+// when the worker is stopped through Stop.
+func (w *EmbeddedWorker) Errors() <-chan error {
+	return w.errs
 }
 
-func (w *EmbeddedWorker) startHealthServer() {
-	mux := http.NewServeMux()
-	
-	// Health check endpoint
-	mux.HandleFunc("/health", func(rw http.ResponseWriter, r *http.Request) {
-		if w.IsRunning() {
-			rw.WriteHeader(http.StatusOK)
-			rw.Write([]byte(`{"status":"healthy","worker":"running"}`))
-		} else {
-			rw.WriteHeader(http.StatusServiceUnavailable)
-			rw.Write([]byte(`{"status":"unhealthy","worker":"stopped"}`))
-		}
-	})
-
-	// Ready check endpoint
-	mux.HandleFunc("/ready", func(rw http.ResponseWriter, r *http.Request) {
-		if w.IsRunning() {
-			rw.WriteHeader(http.StatusOK)
-			rw.Write([]byte(`{"status":"ready"}`))
-		} else {
-			rw.WriteHeader(http.StatusServiceUnavailable)
-			rw.Write([]byte(`{"status":"not_ready"}`))
-		}
-	})
-
-	// Metrics endpoint (basic)
-	mux.HandleFunc("/metrics", func(rw http.ResponseWriter, r *http.Request) {
-		rw.Header().Set("Content-Type", "text/plain")
-		rw.WriteHeader(http.StatusOK)
-		rw.Write([]byte(fmt.Sprintf("temporal_worker_running %d\n", map[bool]int{true: 1, false: 0}[w.IsRunning()])))
-	})
-
-	w.httpServer = &http.Server{
-		Addr:    ":9090",
-		Handler: mux,
+// fail records the error the worker stopped on and reports it on Errors.
+// The SDK stops the worker right after, Stop still has to be called to
+// release the rest.
+func (w *EmbeddedWorker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.logger.Error("Temporal worker stopped on a fatal error", "Error", err)
+	w.fatalErr = err
+	w.setRunning(0)
+
+	// Only the first error matters, the worker is stopped after it
+	select {
+	case w.errs <- err:
+	default:
 	}
+}
 
-	go func() {
-		log.Printf("Starting health server on :9090")
-		if err := w.httpServer.ListenAndServe(); err != http.ErrServerClosed {
-			log.Printf("Health server error: %v", err)
-		}
-	}()
-}
\ No newline at end of file
+func (w *EmbeddedWorker) GetClient() client.Client {
+	return w.client
+}
+
+// setRunning records whether the worker is running in the WorkerRunning gauge
+func (w *EmbeddedWorker) setRunning(value float64) {
+	metrics.Register(w.metrics, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: WorkerRunning,
+		Help: "Whether the Temporal worker is running.",
+	})).Set(value)
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/worker"
+)
+
+// stoppingWorker blocks in Stop until released
+type stoppingWorker struct {
+	worker.Worker
+	release chan struct{}
+}
+
+func (w *stoppingWorker) Stop() {
+	<-w.release
+}
+
+func TestEmbeddedWorker_Errors(t *testing.T) {
+	w := newHealthWorker(&healthClient{namespaceState: enums.NAMESPACE_STATE_REGISTERED})
+
+	w.fail(errors.New("namespace not found"))
+	w.fail(errors.New("stopped"))
+
+	select {
+	case err := <-w.Errors():
+		assert.EqualError(t, err, "namespace not found")
+	default:
+		t.Fatal("expected the fatal error")
+	}
+	assert.Empty(t, w.Errors(), "only the first error is delivered")
+}
+
+func TestEmbeddedWorker_Stop(t *testing.T) {
+	t.Run("waits for the worker", func(t *testing.T) {
+		w := newHealthWorker(&healthClient{})
+		release := make(chan struct{})
+		w.workers = []worker.Worker{&stoppingWorker{release: release}}
+		close(release)
+
+		require.NoError(t, w.Stop(context.Background()))
+		assert.False(t, w.IsRunning())
+		// Stopping again is a no-op
+		assert.NoError(t, w.Stop(context.Background()))
+	})
+
+	t.Run("honors the deadline", func(t *testing.T) {
+		w := newHealthWorker(&healthClient{})
+		release := make(chan struct{})
+		defer close(release)
+		w.workers = []worker.Worker{&stoppingWorker{release: release}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := w.Stop(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.False(t, w.IsRunning())
+	})
+}